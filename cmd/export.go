@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Tarick/tscheduler/pkg/job"
+)
+
+// unsafeUnitChars are characters, which are not allowed in systemd unit names
+var unsafeUnitChars = regexp.MustCompile(`[^a-zA-Z0-9:_.-]+`)
+
+// unsafeShellChars are characters, which require quoting in shell
+var unsafeShellChars = regexp.MustCompile(`[^a-zA-Z0-9_./:=@%+,-]`)
+
+// exportJobs converts configured jobs to crontab lines or systemd timer and service units.
+// Files are written to dir, or printed to stdout if dir is empty. Inexact conversions are reported to stderr.
+func exportJobs(format, dir string) {
+	files := map[string]string{}
+	var names []string
+	add := func(name, content string) {
+		if _, ok := files[name]; !ok {
+			names = append(names, name)
+		}
+		files[name] += content
+	}
+	for _, jobConfig := range jobConfigs {
+		warn := func(msg string) {
+			fmt.Fprintf(os.Stderr, "WARNING: job %v: %s\n", jobConfig.ID, msg)
+		}
+		if jobConfig.FixedDelay != "" {
+			warn("fixed delay jobs can't be exported, skipped")
+			continue
+		}
+		if len(jobConfig.DependsOn) != 0 {
+			warn("jobs with dependencies can't be exported, skipped")
+			continue
+		}
+		if len(jobConfig.ScheduleSpec) == 0 {
+			warn("jobs without schedule can't be exported, skipped")
+			continue
+		}
+		if len(jobConfig.OnSuccess) != 0 || len(jobConfig.OnFailure) != 0 || len(jobConfig.OnComplete) != 0 {
+			warn("triggers of other jobs can't be exported, the job is exported without them")
+		}
+		schedule, err := newSchedule(jobConfig)
+		if err != nil {
+			fmt.Printf("Failure creating schedule for job %v: %v \n", jobConfig.ID, err)
+			os.Exit(1)
+		}
+		var timeout time.Duration
+		if jobConfig.Timeout != "" {
+			if timeout, err = time.ParseDuration(jobConfig.Timeout); err != nil {
+				fmt.Printf("Failure parsing timeout value %s: %v \n", jobConfig.Timeout, err)
+				os.Exit(1)
+			}
+		}
+		concurrency, err := newConcurrencyPolicy(jobConfig)
+		if err != nil {
+			fmt.Printf("Failure parsing concurrency of job %v: %v \n", jobConfig.ID, err)
+			os.Exit(1)
+		}
+		if len(jobConfig.Locks) != 0 {
+			warn("locks can't be exported, runs of jobs with the same lock can overlap")
+		}
+		if len(jobConfig.Resources) != 0 {
+			warn("resources can't be exported, runs are not limited by resource pools")
+		}
+		switch format {
+		case "crontab":
+			if concurrency.Max != 0 {
+				warn("crontab doesn't limit concurrent runs")
+			}
+			command := crontabCommand(jobConfig, timeout)
+			add("tscheduler.crontab", fmt.Sprintf("# %v\n", jobConfig.ID))
+			for i, s := range schedule {
+				expr, warnings := s.Crontab()
+				for _, w := range warnings {
+					warn(fmt.Sprintf("schedule #%d %s", i+1, w))
+				}
+				if expr == "" {
+					continue
+				}
+				add("tscheduler.crontab", expr+" "+command+"\n")
+			}
+		case "systemd":
+			if concurrency.Max != 1 || concurrency.Policy == job.ConcurrencyQueue || concurrency.Policy == job.ConcurrencyReplace {
+				warn("systemd doesn't start the service while it is running, only one run at a time with skip policy is supported")
+			}
+			var calendars []string
+			for i, s := range schedule {
+				exprs, warnings := s.OnCalendar()
+				for _, w := range warnings {
+					warn(fmt.Sprintf("schedule #%d %s", i+1, w))
+				}
+				calendars = append(calendars, exprs...)
+			}
+			if len(calendars) == 0 {
+				warn("no schedules could be exported, skipped")
+				continue
+			}
+			unit := "tscheduler-" + strings.Trim(unsafeUnitChars.ReplaceAllString(jobConfig.ID, "-"), "-")
+			add(unit+".service", systemdService(jobConfig, timeout))
+			add(unit+".timer", systemdTimer(jobConfig, calendars))
+		default:
+			fmt.Printf("Unsupported export format %s, must be crontab or systemd\n", format)
+			os.Exit(1)
+		}
+	}
+	for _, name := range names {
+		if dir == "" {
+			fmt.Printf("# File: %s\n%s\n", name, files[name])
+			continue
+		}
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(files[name]), 0644); err != nil {
+			fmt.Printf("Failure writing %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		fmt.Println("Written", path)
+	}
+}
+
+// seconds formats duration as seconds, understood by timeout(1) and systemd
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64) + "s"
+}
+
+// crontabCommand returns shell command line with timeout and output redirection, "%" is escaped for crontab
+func crontabCommand(jobConfig jobConfig, timeout time.Duration) string {
+	var args []string
+	if timeout != 0 {
+		args = append(args, "timeout", seconds(timeout))
+	}
+	for _, arg := range jobConfig.Command {
+		if arg == "" || unsafeShellChars.MatchString(arg) {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		args = append(args, arg)
+	}
+	command := strings.Join(args, " ")
+	if jobConfig.Stdout != "" {
+		command += " >> '" + strings.ReplaceAll(jobConfig.Stdout, "'", `'\''`) + "'"
+	}
+	if jobConfig.Stderr != "" {
+		command += " 2>> '" + strings.ReplaceAll(jobConfig.Stderr, "'", `'\''`) + "'"
+	}
+	return strings.ReplaceAll(command, "%", `\%`)
+}
+
+// systemdEscape quotes unit file value, escaping specifiers and variables
+func systemdEscape(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$").Replace(s)
+	return `"` + s + `"`
+}
+
+func systemdService(jobConfig jobConfig, timeout time.Duration) string {
+	args := make([]string, len(jobConfig.Command))
+	for i, arg := range jobConfig.Command {
+		args[i] = systemdEscape(arg)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Unit]\nDescription=tscheduler job %s\n\n[Service]\nType=oneshot\nExecStart=%s\n",
+		jobConfig.ID, strings.Join(args, " "))
+	if jobConfig.Stdout != "" {
+		fmt.Fprintf(&b, "StandardOutput=append:%s\n", jobConfig.Stdout)
+	}
+	if jobConfig.Stderr != "" {
+		fmt.Fprintf(&b, "StandardError=append:%s\n", jobConfig.Stderr)
+	}
+	if timeout != 0 {
+		fmt.Fprintf(&b, "TimeoutStartSec=%s\n", seconds(timeout))
+	}
+	return b.String()
+}
+
+func systemdTimer(jobConfig jobConfig, calendars []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[Unit]\nDescription=Timer for tscheduler job %s\n\n[Timer]\n", jobConfig.ID)
+	for _, c := range calendars {
+		fmt.Fprintf(&b, "OnCalendar=%s\n", c)
+	}
+	// Default accuracy is 1 minute
+	fmt.Fprintf(&b, "AccuracySec=1s\n\n[Install]\nWantedBy=timers.target\n")
+	return b.String()
+}
@@ -2,25 +2,31 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"text/template"
 	"time"
 
 	"github.com/Tarick/tscheduler/pkg/job"
+	"github.com/Tarick/tscheduler/pkg/scheduler"
 )
 
 func startManagementService() {
 	mngMux := http.NewServeMux()
 	mngMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprintln(w, "Available commands: /start, /stop, /status, /shutdown")
+		fmt.Fprintln(w, "Available commands: /start, /stop, /status, /workflows, /shutdown, POST /jobs/<id>/run")
 	})
 	// mngMux.Handle("/status", mngStatusHandler)
 	mngMux.HandleFunc("/pause", mngPauseHandler)
 	mngMux.HandleFunc("/resume", mngResumeHandler)
 	mngMux.HandleFunc("/status", mngStatusHandler)
+	mngMux.HandleFunc("/workflows", mngWorkflowsHandler)
+	mngMux.HandleFunc("/jobs/", mngRunJobHandler)
 	mngMux.HandleFunc("/shutdown", mngShutdownHandler)
 	mngServer := http.Server{
 		Addr:         management.Address,
@@ -55,50 +61,148 @@ func mngResumeHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func mngStatusHandler(w http.ResponseWriter, r *http.Request) {
+	type JobStatus struct {
+		Job     job.Job
+		Summary scheduler.RunSummary
+		History []scheduler.RunRecord
+	}
 	type StatusData struct {
 		SchedulerIsRunning bool
-		Jobs               []job.Job
+		Jobs               []JobStatus
+		Pools              map[string]scheduler.PoolStats
+		Queue              scheduler.QueueStats
 	}
 	st := StatusData{}
 	st.SchedulerIsRunning = sr.IsRunning()
-	st.Jobs = sr.GetJobs()
+	st.Pools = sr.Pools()
+	st.Queue = sr.Queue()
+	for _, j := range sr.GetJobs() {
+		js := JobStatus{Job: j}
+		var err error
+		if js.Summary, err = sr.Summary(j.Id); err != nil {
+			log.Error("Failure getting job ", j.Id, " runs summary: ", err)
+		}
+		if js.History, err = sr.History(j.Id); err != nil {
+			log.Error("Failure getting job ", j.Id, " history: ", err)
+		}
+		st.Jobs = append(st.Jobs, js)
+	}
 
 	t := template.New("status")
 	t.Parse(`Scheduler is running: {{.SchedulerIsRunning}}
+Running jobs: {{ .Queue.Workers }}{{ if .Queue.MaxWorkers }} of max {{ .Queue.MaxWorkers }}{{ end }}, waiting in queue: {{ .Queue.Waiting }}
+{{- if .Pools }}
+Resource pools:
+{{- range $name, $pool := .Pools }}
+  {{ $name }}: {{ $pool }}
+{{- end }}
+{{- end }}
 Jobs registerd on scheduler and their stats:
 {{ range .Jobs }}
-{{ . }} 
+{{ .Job }} 
+Runs summary: {{ .Summary }}
+{{- if .History }}
+Last runs:
+{{- range .History }}
+  {{ . }}
+{{- end }}
+{{- end }}
 
 {{ end }} `)
 	t.Execute(w, st)
 }
 
+func mngWorkflowsHandler(w http.ResponseWriter, r *http.Request) {
+	t := template.New("workflows")
+	t.Parse(`Workflow runs, the newest first:
+{{ range . }}
+{{ . }}
+{{ else }}
+No workflow runs
+{{ end }}`)
+	t.Execute(w, sr.Workflows())
+}
+
+// Starts the job run on POST /jobs/<id>/run, optional "scheduled" query parameter is the run scheduled time in RFC3339 format
+func mngRunJobHandler(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/jobs/")
+	if !strings.HasSuffix(id, "/run") {
+		http.NotFound(w, r)
+		return
+	}
+	id = strings.TrimSuffix(id, "/run")
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method not allowed, use POST", http.StatusMethodNotAllowed)
+		return
+	}
+	var opts scheduler.TriggerOptions
+	if scheduled := r.URL.Query().Get("scheduled"); scheduled != "" {
+		t, err := time.Parse(time.RFC3339, scheduled)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failure parsing scheduled time %s: %v", scheduled, err), http.StatusBadRequest)
+			return
+		}
+		opts.Scheduled = t
+	}
+	runID, err := sr.Trigger(id, opts)
+	switch {
+	case errors.Is(err, scheduler.ErrUnknownJob):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, scheduler.ErrNotRunning):
+		http.Error(w, err.Error()+", resume it to run jobs", http.StatusConflict)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		log.Info("Job ", id, " run ", runID, " is started on demand via management service")
+		fmt.Fprintf(w, "Started job %s run %s, it may wait or be skipped by the job concurrency policy, locks and resources\n", id, runID)
+	}
+}
+
 // Shutdown a scheduler, the process will exit. Kills jobs after the configurable termination period.
 func mngShutdownHandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintln(w, mngStopScheduler())
-	log.Warn("Sending global shutdown signal to any available job")
+	log.Warn("Cancelling any still running job")
 	fmt.Fprintln(w, "Sent message to any still running job to be killed.")
 	fmt.Fprintln(w, "Terminating!!!")
-	close(jobsCancel)
-	log.Debug("Sleeping for ", management.JobsTerminationTimeout, "s to allow jobs to be killed.")
-	time.Sleep(time.Duration(management.JobsTerminationTimeout) * time.Second)
+	ctx, cancel := context.WithTimeout(sr.Shutdown(), time.Duration(management.JobsTerminationTimeout)*time.Second)
+	defer cancel()
+	log.Debug("Waiting up to ", management.JobsTerminationTimeout, "s for jobs to be killed.")
+	<-ctx.Done()
+	if ctx.Err() == context.DeadlineExceeded {
+		log.Warn("Jobs termination timeout exceeded, there were left running jobs")
+	}
 	// Send termination to main thread
 	done <- struct{}{}
 }
 
 func callManagementEndpoint(endpoint string) string {
+	return requestManagementEndpoint(http.MethodGet, endpoint)
+}
+
+// requestManagementEndpoint sends the request to management service, exits on failure
+func requestManagementEndpoint(method, endpoint string) string {
 	if !management.Enabled {
 		fmt.Println("ERROR - management is not enabled in config. Stopping and starting won't work without management enabled and scheduler started with it.")
 		os.Exit(1)
 	}
 	managementURL := "http://" + management.Address + endpoint
-	resp, err := http.Get(managementURL)
+	req, err := http.NewRequest(method, managementURL, nil)
+	if err != nil {
+		fmt.Printf("ERROR - failed query of management url: %v", err)
+		os.Exit(1)
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		fmt.Printf("ERROR - failed query of management url: %v", err)
 		os.Exit(1)
 	}
 	defer resp.Body.Close()
 	body, err := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode >= http.StatusBadRequest {
+		fmt.Printf("ERROR - %s", body)
+		os.Exit(1)
+	}
 	return string(body)
 }
 
@@ -111,6 +215,16 @@ func stopScheduler() {
 func getStatus() {
 	fmt.Println(callManagementEndpoint("/status"))
 }
+func getWorkflows() {
+	fmt.Println(callManagementEndpoint("/workflows"))
+}
 func shutdown() {
 	fmt.Println(callManagementEndpoint("/shutdown"))
 }
+func runJob(id, scheduled string) {
+	endpoint := "/jobs/" + url.PathEscape(id) + "/run"
+	if scheduled != "" {
+		endpoint += "?scheduled=" + url.QueryEscape(scheduled)
+	}
+	fmt.Print(requestManagementEndpoint(http.MethodPost, endpoint))
+}
@@ -22,11 +22,73 @@ var (
 		},
 		[]string{"job_id", "status"},
 	)
+	metricJobsRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "tscheduler_jobs_retries_by_jobid",
+			Help: "Total number of jobs retry attempts with job_id label.",
+		},
+		[]string{"job_id"},
+	)
 	metricJobsCountRegisteredDesc = prometheus.NewDesc(
 		"tscheduler_jobs_registered",
 		"Number of jobs that are registered in scheduler for processing.",
 		nil, nil,
 	)
+	metricJobsQueuedDesc = prometheus.NewDesc(
+		"tscheduler_jobs_queued_by_jobid",
+		"Number of job runs, waiting by job concurrency policy, with job_id label.",
+		[]string{"job_id"}, nil,
+	)
+	metricJobsSkippedDesc = prometheus.NewDesc(
+		"tscheduler_jobs_concurrency_skipped_by_jobid",
+		"Total number of job runs, skipped by job concurrency or lock policy, with job_id label.",
+		[]string{"job_id"}, nil,
+	)
+	metricQueueWorkersDesc = prometheus.NewDesc(
+		"tscheduler_queue_workers",
+		"Number of running jobs, limited by max_concurrent_jobs.",
+		nil, nil,
+	)
+	metricQueueLengthDesc = prometheus.NewDesc(
+		"tscheduler_queue_length",
+		"Number of job runs, waiting in the queue for locks, resources or workers.",
+		nil, nil,
+	)
+	metricQueueWaitDesc = prometheus.NewDesc(
+		"tscheduler_queue_wait_seconds",
+		"Time in seconds, started job runs have waited in the queue.",
+		nil, nil,
+	)
+	metricPoolsSizeDesc = prometheus.NewDesc(
+		"tscheduler_pools_size_by_pool",
+		"Resource pool size, with pool label.",
+		[]string{"pool"}, nil,
+	)
+	metricPoolsUsedDesc = prometheus.NewDesc(
+		"tscheduler_pools_used_by_pool",
+		"Sum of resource weights of running jobs in the pool, with pool label.",
+		[]string{"pool"}, nil,
+	)
+	metricPoolsWaitingDesc = prometheus.NewDesc(
+		"tscheduler_pools_waiting_by_pool",
+		"Number of job runs, waiting for the pool capacity, with pool label.",
+		[]string{"pool"}, nil,
+	)
+	metricLocksHeldDesc = prometheus.NewDesc(
+		"tscheduler_locks_held_by_lock",
+		"Lock state, 1 if the lock is held by job run, with lock label.",
+		[]string{"lock"}, nil,
+	)
+	metricLocksWaitingDesc = prometheus.NewDesc(
+		"tscheduler_locks_waiting_by_lock",
+		"Number of job runs, waiting for the lock, with lock label.",
+		[]string{"lock"}, nil,
+	)
+	metricLocksWaitSecondsDesc = prometheus.NewDesc(
+		"tscheduler_locks_wait_seconds_by_lock",
+		"Total time in seconds, job runs have waited for the lock, with lock label.",
+		[]string{"lock"}, nil,
+	)
 )
 
 // SchedulerCollector implements the Collector interface.
@@ -53,6 +115,28 @@ func (sc SchedulerCollector) Collect(ch chan<- prometheus.Metric) {
 		prometheus.GaugeValue,
 		float64(numberOfRegisteredJobs),
 	)
+	for id, stats := range sc.Scheduler.Stats() {
+		ch <- prometheus.MustNewConstMetric(metricJobsQueuedDesc, prometheus.GaugeValue, float64(stats.Queued), id)
+		ch <- prometheus.MustNewConstMetric(metricJobsSkippedDesc, prometheus.CounterValue, float64(stats.Skipped), id)
+	}
+	for name, stats := range sc.Scheduler.Locks() {
+		held := 0.0
+		if stats.Holder != "" {
+			held = 1
+		}
+		ch <- prometheus.MustNewConstMetric(metricLocksHeldDesc, prometheus.GaugeValue, held, name)
+		ch <- prometheus.MustNewConstMetric(metricLocksWaitingDesc, prometheus.GaugeValue, float64(stats.Waiting), name)
+		ch <- prometheus.MustNewConstMetric(metricLocksWaitSecondsDesc, prometheus.CounterValue, stats.WaitTime.Seconds(), name)
+	}
+	queue := sc.Scheduler.Queue()
+	ch <- prometheus.MustNewConstMetric(metricQueueWorkersDesc, prometheus.GaugeValue, float64(queue.Workers))
+	ch <- prometheus.MustNewConstMetric(metricQueueLengthDesc, prometheus.GaugeValue, float64(queue.Waiting))
+	ch <- prometheus.MustNewConstSummary(metricQueueWaitDesc, uint64(queue.Started), queue.WaitTime.Seconds(), nil)
+	for name, stats := range sc.Scheduler.Pools() {
+		ch <- prometheus.MustNewConstMetric(metricPoolsSizeDesc, prometheus.GaugeValue, float64(stats.Size), name)
+		ch <- prometheus.MustNewConstMetric(metricPoolsUsedDesc, prometheus.GaugeValue, float64(stats.Used), name)
+		ch <- prometheus.MustNewConstMetric(metricPoolsWaitingDesc, prometheus.GaugeValue, float64(stats.Waiting), name)
+	}
 }
 
 func startMetricsService() {
@@ -63,6 +147,7 @@ func startMetricsService() {
 	prometheus.MustRegister(SchedulerCollector{Scheduler: sr})
 	prometheus.MustRegister(metricJobsFinished)
 	prometheus.MustRegister(metricJobsRunning)
+	prometheus.MustRegister(metricJobsRetries)
 	metricsMux := http.NewServeMux()
 	metricsServer := http.Server{
 		Addr:         metrics.Address,
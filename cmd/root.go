@@ -14,27 +14,74 @@ import (
 )
 
 var (
-	cfgFile    string
-	verbose    bool
-	jobConfigs []jobConfig
-	metrics    metricsConfig
-	management managementConfig
-	log        *zap.SugaredLogger
+	cfgFile            string
+	verbose            bool
+	jobConfigs         []jobConfig
+	calendars          map[string]job.CalendarSpec
+	metrics            metricsConfig
+	management         managementConfig
+	history            historyConfig
+	state              stateConfig
+	pools              map[string]int
+	queue              queueConfig
+	clockJumpThreshold string
+	log                *zap.SugaredLogger
 )
 
 type jobConfig struct {
-	ID           string             `mapstructure:"id"`
-	Command      []string           `mapstructure:"command"`
-	Stdout       string             `mapstructure:"stdout"`
-	Stderr       string             `mapstructure:"stderr"`
-	Parallel     bool               `mapstructure:"parallel"`
-	Timeout      string             `mapstructure:"timeout"`
-	ScheduleSpec []job.ScheduleSpec `mapstructure:"schedule"`
+	ID             string             `mapstructure:"id"`
+	Command        []string           `mapstructure:"command"`
+	Stdout         string             `mapstructure:"stdout"`
+	Stderr         string             `mapstructure:"stderr"`
+	Parallel       bool               `mapstructure:"parallel"`
+	Concurrency    *concurrencyConfig `mapstructure:"concurrency"`
+	Locks          []string           `mapstructure:"locks"`
+	LockPolicy     string             `mapstructure:"lock_policy"`
+	Resources      map[string]int     `mapstructure:"resources"`
+	ResourcePolicy string             `mapstructure:"resource_policy"`
+	Priority       int                `mapstructure:"priority"`
+	Timeout        string             `mapstructure:"timeout"`
+	FixedDelay     string             `mapstructure:"fixed_delay"`
+	MisfirePolicy  string             `mapstructure:"misfire_policy"`
+	MisfireMaxRuns int                `mapstructure:"misfire_max_runs"`
+	MisfireGrace   string             `mapstructure:"misfire_grace"`
+	Retry          *retryConfig       `mapstructure:"retry"`
+	DependsOn      []string           `mapstructure:"depends_on"`
+	OnSuccess      []string           `mapstructure:"on_success"`
+	OnFailure      []string           `mapstructure:"on_failure"`
+	OnComplete     []string           `mapstructure:"on_complete"`
+	ScheduleSpec   []job.ScheduleSpec `mapstructure:"schedule"`
+}
+type concurrencyConfig struct {
+	Max       int    `mapstructure:"max"`
+	Policy    string `mapstructure:"policy"`
+	MaxQueued int    `mapstructure:"max_queued"`
+}
+type retryConfig struct {
+	MaxAttempts  int     `mapstructure:"max_attempts"`
+	InitialDelay string  `mapstructure:"initial_delay"`
+	MaxDelay     string  `mapstructure:"max_delay"`
+	Multiplier   float64 `mapstructure:"multiplier"`
+	Jitter       float64 `mapstructure:"jitter"`
+	ExitCodes    []int   `mapstructure:"exit_codes"`
 }
 type metricsConfig struct {
 	Enabled bool   `mapstructure:"enabled"`
 	Address string `mapstructure:"address"`
 }
+type queueConfig struct {
+	MaxConcurrentJobs int
+	OverrunPolicy     string
+	PriorityAging     string
+}
+type stateConfig struct {
+	Path string `mapstructure:"path"`
+}
+type historyConfig struct {
+	Path    string `mapstructure:"path"`
+	MaxRuns int    `mapstructure:"max_runs"`
+	MaxAge  string `mapstructure:"max_age"`
+}
 type managementConfig struct {
 	Enabled                bool   `mapstructure:"enabled"`
 	Address                string `mapstructure:"address"`
@@ -51,8 +98,16 @@ var rootCmd = &cobra.Command{
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	//	Run: func(cmd *cobra.Command, args []string) { },
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if cmd.Annotations[noConfigAnnotation] == "" {
+			initConfig()
+		}
+	},
 }
 
+// noConfigAnnotation marks commands, which work without config file, e.g. schedule text check
+const noConfigAnnotation = "noconfig"
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
@@ -62,8 +117,6 @@ func Execute() {
 	}
 }
 func init() {
-	cobra.OnInitialize(initConfig)
-
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
@@ -104,6 +157,13 @@ func initConfig() {
 		fmt.Println("Fatal: jobs are not defined in config file")
 		os.Exit(1)
 	}
+	if viper.IsSet("calendars") {
+		viper.UnmarshalKey("calendars", &calendars)
+	}
+	if err := resolveCalendars(); err != nil {
+		fmt.Printf("FATAL: %v\n", err)
+		os.Exit(1)
+	}
 	if viper.IsSet("metrics") {
 		viper.UnmarshalKey("metrics", &metrics)
 	} else {
@@ -114,9 +174,45 @@ func initConfig() {
 	} else {
 		management.Enabled = false
 	}
+	if viper.IsSet("history") {
+		viper.UnmarshalKey("history", &history)
+	}
+	if viper.IsSet("state") {
+		viper.UnmarshalKey("state", &state)
+	}
+	if viper.IsSet("pools") {
+		viper.UnmarshalKey("pools", &pools)
+	}
+	queue.MaxConcurrentJobs = viper.GetInt("max_concurrent_jobs")
+	queue.OverrunPolicy = viper.GetString("queue_overrun_policy")
+	queue.PriorityAging = viper.GetString("priority_aging")
+	clockJumpThreshold = viper.GetString("clock_jump_threshold")
 	log = initLogger()
 }
 
+// resolveCalendars parses shared calendars and attaches them to business day schedules of jobs, which refer them by name
+func resolveCalendars() error {
+	parsed := make(map[string]*job.Calendar, len(calendars))
+	for name, spec := range calendars {
+		c, err := job.NewCalendar(spec)
+		if err != nil {
+			return fmt.Errorf("failure parsing calendar %s: %v", name, err)
+		}
+		parsed[name] = c
+	}
+	for _, jobConfig := range jobConfigs {
+		for _, spec := range jobConfig.ScheduleSpec {
+			if spec.BusinessDay == nil {
+				continue
+			}
+			if err := spec.BusinessDay.ResolveCalendar(parsed); err != nil {
+				return fmt.Errorf("failure parsing job %s business day schedule: %v", jobConfig.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
 func initLogger() *zap.SugaredLogger {
 	type LogConfig struct {
 		Development       bool     `mapstructure:"development"`
@@ -1,36 +1,27 @@
 package cmd
 
 import (
-
-	// "time"
-
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
 	"time"
 
 	"github.com/Tarick/tscheduler/pkg/job"
 	"github.com/Tarick/tscheduler/pkg/scheduler"
-
-	"golang.org/x/sync/semaphore"
 )
 
 var (
-	sr         *scheduler.Scheduler
-	done       chan struct{}
-	jobsCancel chan struct{}
+	sr   *scheduler.Scheduler
+	done chan struct{}
 )
 
 // This creates command to run
-func createCommand(jobConfig jobConfig) func() {
-	var parallelSemaphore *semaphore.Weighted
+func createCommand(jobConfig jobConfig) func(ctx context.Context, run job.RunInfo) error {
 	var timeout time.Duration
 	var err error
 
-	// Ensure that we hold the semaphore if job is not parallel
-	if !jobConfig.Parallel {
-		parallelSemaphore = semaphore.NewWeighted(1)
-	}
 	if jobConfig.Timeout != "" {
 		timeout, err = time.ParseDuration(jobConfig.Timeout)
 		if err != nil {
@@ -41,8 +32,7 @@ func createCommand(jobConfig jobConfig) func() {
 		timeout = 0 * time.Second
 	}
 	// This func is returned as job.command to be run
-	return func() {
-		var err error
+	return func(ctx context.Context, run job.RunInfo) (err error) {
 		// jobStatus is for metrics population
 		var jobStatus string
 		if metrics.Enabled {
@@ -51,113 +41,341 @@ func createCommand(jobConfig jobConfig) func() {
 			defer func(status *string) {
 				metricJobsFinished.WithLabelValues(jobConfig.ID, *status).Inc()
 			}(&jobStatus)
+			if run.Attempt > 1 {
+				metricJobsRetries.WithLabelValues(jobConfig.ID).Inc()
+			}
+		}
+		if timeout != 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		// Process is killed when context is cancelled on timeout or scheduler shutdown
+		cmd := exec.CommandContext(ctx, jobConfig.Command[0], jobConfig.Command[1:]...)
+		cmd.Env = append(os.Environ(),
+			"TSCHEDULER_JOB_ID="+jobConfig.ID,
+			"TSCHEDULER_RUN_ID="+run.ID,
+			"TSCHEDULER_SCHEDULED_TIME="+run.Scheduled.Format(time.RFC3339),
+			"TSCHEDULER_ATTEMPT="+strconv.Itoa(run.Attempt),
+		)
+		if run.WorkflowID != "" {
+			cmd.Env = append(cmd.Env, "TSCHEDULER_WORKFLOW_RUN_ID="+run.WorkflowID)
+		}
+		if run.Manual {
+			cmd.Env = append(cmd.Env, "TSCHEDULER_MANUAL_RUN=true")
+		}
+		if run.Trigger.JobID != "" {
+			cmd.Env = append(cmd.Env,
+				"TSCHEDULER_TRIGGER_JOB_ID="+run.Trigger.JobID,
+				"TSCHEDULER_TRIGGER_RUN_ID="+run.Trigger.RunID,
+				"TSCHEDULER_TRIGGER_STATUS="+run.Trigger.Status,
+			)
 		}
-		cmd := exec.Command(jobConfig.Command[0], jobConfig.Command[1:]...)
 		if jobConfig.Stdout != "" {
-			cmd.Stdout, err = os.OpenFile(jobConfig.Stdout, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+			stdout, err := os.OpenFile(jobConfig.Stdout, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
 			if err != nil {
-				log.Error(jobConfig.ID, " failure opening stdout file for writing: ", err)
 				jobStatus = "skipped"
-				return
+				return fmt.Errorf("failure opening stdout file for writing: %v: %w", err, job.ErrSkipped)
 			}
+			defer stdout.Close()
+			cmd.Stdout = stdout
 		}
 		if jobConfig.Stderr != "" {
-			cmd.Stderr, err = os.OpenFile(jobConfig.Stderr, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+			stderr, err := os.OpenFile(jobConfig.Stderr, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
 			if err != nil {
-				log.Error(jobConfig.ID, " failure opening stderr file for writing: ", err)
 				jobStatus = "skipped"
-				return
+				return fmt.Errorf("failure opening stderr file for writing: %v: %w", err, job.ErrSkipped)
 			}
+			defer stderr.Close()
+			cmd.Stderr = stderr
 		}
-		// If parallelSemaphore is not nil, then this job is NOT allowed to run simultaneously
-		if parallelSemaphore != nil {
-			if !parallelSemaphore.TryAcquire(1) {
-				log.Warn("Job ", jobConfig.ID, " is already running and its parallel run is disabled, skipped execution.")
-				jobStatus = "skipped"
-				return
-			}
-			defer parallelSemaphore.Release(1)
-		}
-		// Goroutine that actually starts command
-		done := make(chan error, 1)
-		go func() {
-			defer close(done)
-			log.Debug("Starting ", jobConfig.ID, " command: ", jobConfig.Command)
-			err = cmd.Start()
+		log.Debug("Starting ", jobConfig.ID, " run ", run.ID, " command: ", jobConfig.Command)
+		err = cmd.Run()
+		// Finally check the status of job
+		switch {
+		case err == nil:
+			jobStatus = "success"
+			return nil
+		case ctx.Err() == context.DeadlineExceeded:
+			err = fmt.Errorf("job was killed as has reached job timeout: %w", err)
+		case ctx.Err() == context.Canceled:
+			err = fmt.Errorf("run was cancelled, process was killed: %w", err)
+		}
+		jobStatus = "failed"
+		return err
+	}
+}
+
+// newSchedule parses all schedule specs of the job
+func newSchedule(jobConfig jobConfig) ([]job.Schedule, error) {
+	schedule := []job.Schedule{}
+	for _, scheduleSpec := range jobConfig.ScheduleSpec {
+		s, err := job.NewSchedule(scheduleSpec)
+		if err != nil {
+			return nil, err
+		}
+		schedule = append(schedule, s)
+	}
+	return schedule, nil
+}
+
+// newJobOptions converts optional job settings
+func newJobOptions(jobConfig jobConfig) ([]job.Option, error) {
+	var opts []job.Option
+	if jobConfig.FixedDelay != "" {
+		delay, err := time.ParseDuration(jobConfig.FixedDelay)
+		if err != nil {
+			return nil, fmt.Errorf("failure parsing fixed_delay value %s: %w", jobConfig.FixedDelay, err)
+		}
+		opts = append(opts, job.WithFixedDelay(delay))
+	}
+	if jobConfig.MisfirePolicy != "" {
+		policy := job.MisfirePolicy{Policy: jobConfig.MisfirePolicy, MaxRuns: jobConfig.MisfireMaxRuns}
+		if jobConfig.MisfireGrace != "" {
+			grace, err := time.ParseDuration(jobConfig.MisfireGrace)
 			if err != nil {
-				done <- fmt.Errorf(jobConfig.ID, " command start failed: ", err)
-				return
+				return nil, fmt.Errorf("failure parsing misfire_grace value %s: %w", jobConfig.MisfireGrace, err)
 			}
-			log.Debug(jobConfig.ID, " - waiting for process to finish: ", jobConfig.Command)
-			// Success or error from command if any passed to caller
-			done <- cmd.Wait()
-		}()
-		// if timeout is present, wait for job to exit or kill
-		// TODO: can we optimize with context here? too much boilerplate
-		if timeout != 0 {
-			select {
-			case <-time.After(timeout):
-				err = cmd.Process.Kill()
-				if err != nil {
-					err = fmt.Errorf("job was killed as has reached job timeout, but failed to kill process: %v", err)
-				} else {
-					err = fmt.Errorf("job was killed as has reached job timeout")
-				}
-			// Wait for global termination signal (channel close), when shutting down the program
-			case <-jobsCancel:
-				log.Debug("Job ", jobConfig.ID, " received global jobs shutdown signal, terminating")
-				err = cmd.Process.Kill()
-				if err != nil {
-					err = fmt.Errorf("received global shutdown signal, but failed to terminate process: %v", err)
-				} else {
-					err = fmt.Errorf("received global shutdown signal, process was killed")
-				}
-			case err = <-done:
+			policy.Grace = grace
+		}
+		if err := policy.Validate(); err != nil {
+			return nil, err
+		}
+		opts = append(opts, job.WithMisfirePolicy(policy))
+	}
+	if len(jobConfig.DependsOn) != 0 {
+		opts = append(opts, job.WithDependsOn(jobConfig.DependsOn...))
+	}
+	if len(jobConfig.OnSuccess) != 0 || len(jobConfig.OnFailure) != 0 || len(jobConfig.OnComplete) != 0 {
+		opts = append(opts, job.WithTriggers(job.Triggers{
+			OnSuccess:  jobConfig.OnSuccess,
+			OnFailure:  jobConfig.OnFailure,
+			OnComplete: jobConfig.OnComplete,
+		}))
+	}
+	concurrency, err := newConcurrencyPolicy(jobConfig)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, job.WithConcurrencyPolicy(concurrency))
+	if len(jobConfig.Locks) != 0 {
+		locks := job.Locks{Names: jobConfig.Locks, Policy: jobConfig.LockPolicy}
+		if err := locks.Validate(); err != nil {
+			return nil, err
+		}
+		opts = append(opts, job.WithLocks(locks))
+	}
+	if jobConfig.Priority != 0 {
+		opts = append(opts, job.WithPriority(jobConfig.Priority))
+	}
+	if len(jobConfig.Resources) != 0 {
+		resources := job.Resources{Weights: jobConfig.Resources, Policy: jobConfig.ResourcePolicy}
+		if err := resources.Validate(); err != nil {
+			return nil, err
+		}
+		opts = append(opts, job.WithResources(resources))
+	}
+	if jobConfig.Retry != nil {
+		policy, err := newRetryPolicy(*jobConfig.Retry)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, job.WithRetryPolicy(policy))
+	}
+	return opts, nil
+}
+
+// validateDependencies checks jobs dependencies for unknown jobs and cycles
+func validateDependencies() error {
+	dependencies := make(map[string][]string, len(jobConfigs))
+	for _, jobConfig := range jobConfigs {
+		dependencies[jobConfig.ID] = jobConfig.DependsOn
+	}
+	return scheduler.ValidateDependencies(dependencies)
+}
+
+// jobTriggers returns IDs of jobs, triggered by every job
+func jobTriggers() map[string][]string {
+	triggers := make(map[string][]string, len(jobConfigs))
+	for _, jobConfig := range jobConfigs {
+		var ids []string
+		ids = append(ids, jobConfig.OnSuccess...)
+		ids = append(ids, jobConfig.OnFailure...)
+		triggers[jobConfig.ID] = append(ids, jobConfig.OnComplete...)
+	}
+	return triggers
+}
+
+// validateTriggers checks jobs triggers for unknown jobs and cycles
+func validateTriggers() error {
+	return scheduler.ValidateTriggers(jobTriggers())
+}
+
+// validateStartable checks that jobs without schedule and fixed delay depend on other jobs or are triggered by them
+func validateStartable() error {
+	dependencies := make(map[string][]string, len(jobConfigs))
+	var unscheduled []string
+	for _, jobConfig := range jobConfigs {
+		dependencies[jobConfig.ID] = jobConfig.DependsOn
+		if len(jobConfig.ScheduleSpec) == 0 && jobConfig.FixedDelay == "" {
+			unscheduled = append(unscheduled, jobConfig.ID)
+		}
+	}
+	return scheduler.ValidateStartable(unscheduled, dependencies, jobTriggers())
+}
+
+// validatePools checks resource pool sizes and that jobs resources fit the pools
+func validatePools() error {
+	for name, size := range pools {
+		if size <= 0 {
+			return fmt.Errorf("resource pool %v size must be positive", name)
+		}
+	}
+	for _, jobConfig := range jobConfigs {
+		for name, weight := range jobConfig.Resources {
+			size, ok := pools[name]
+			if !ok {
+				return fmt.Errorf("job %v requires unknown resource pool %v", jobConfig.ID, name)
 			}
-		} else {
-			select {
-			// Wait for global termination signal (channel close), when shutting down the program
-			case <-jobsCancel:
-				log.Warn("Job ", jobConfig.ID, " received global jobs shutdown signal, terminating")
-				err = cmd.Process.Kill()
-				if err != nil {
-					err = fmt.Errorf("received global shutdown signal, but failed to terminate process: %v", err)
-				} else {
-					err = fmt.Errorf("received global shutdown signal, process was killed")
-				}
-			case err = <-done:
+			if weight > size {
+				return fmt.Errorf("job %v requires %d of resource pool %v, which size is %d", jobConfig.ID, weight, name, size)
 			}
 		}
-		// Finally check the status of job
+	}
+	return nil
+}
+
+// newConcurrencyPolicy converts concurrency settings of the job, deprecated "parallel: true" means no limit
+func newConcurrencyPolicy(jobConfig jobConfig) (job.ConcurrencyPolicy, error) {
+	switch {
+	case jobConfig.Concurrency != nil && jobConfig.Parallel:
+		return job.ConcurrencyPolicy{}, fmt.Errorf("parallel is deprecated and can't be used with concurrency")
+	case jobConfig.Concurrency != nil:
+		policy := job.ConcurrencyPolicy{
+			Max:       jobConfig.Concurrency.Max,
+			Policy:    jobConfig.Concurrency.Policy,
+			MaxQueued: jobConfig.Concurrency.MaxQueued,
+		}
+		return policy, policy.Validate()
+	case jobConfig.Parallel:
+		return job.ConcurrencyPolicy{}, nil
+	}
+	return job.ConcurrencyPolicy{Max: 1}, nil
+}
+
+// newRetryPolicy converts retry settings of the job
+func newRetryPolicy(config retryConfig) (job.RetryPolicy, error) {
+	policy := job.RetryPolicy{
+		MaxAttempts: config.MaxAttempts,
+		Multiplier:  config.Multiplier,
+		Jitter:      config.Jitter,
+		ExitCodes:   config.ExitCodes,
+	}
+	var err error
+	if config.InitialDelay != "" {
+		if policy.InitialDelay, err = time.ParseDuration(config.InitialDelay); err != nil {
+			return policy, fmt.Errorf("failure parsing retry initial_delay value %s: %w", config.InitialDelay, err)
+		}
+	}
+	if config.MaxDelay != "" {
+		if policy.MaxDelay, err = time.ParseDuration(config.MaxDelay); err != nil {
+			return policy, fmt.Errorf("failure parsing retry max_delay value %s: %w", config.MaxDelay, err)
+		}
+	}
+	return policy, policy.Validate()
+}
+
+// newHistoryStore creates run history store, file backed if the path is configured
+func newHistoryStore(config historyConfig) (scheduler.HistoryStore, error) {
+	retention := scheduler.HistoryRetention{MaxRuns: config.MaxRuns}
+	if config.MaxAge != "" {
+		maxAge, err := time.ParseDuration(config.MaxAge)
 		if err != nil {
-			jobStatus = "failed"
-			log.Error(jobConfig.ID, " failed with error: ", err)
-		} else {
-			// log.Debug(j.id, " job output: ", string(output))
-			jobStatus = "success"
-			log.Info(jobConfig.ID, " job finished succesfully")
+			return nil, fmt.Errorf("failure parsing max_age value %s: %w", config.MaxAge, err)
 		}
+		retention.MaxAge = maxAge
+	}
+	if config.Path == "" {
+		return scheduler.NewMemoryHistoryStore(retention), nil
 	}
+	return scheduler.NewFileHistoryStore(config.Path, retention)
 }
 
 func runScheduler() {
 	// For this thread
 	done = make(chan struct{})
-	// For all jobs
-	jobsCancel = make(chan struct{})
 
-	sr = scheduler.New(log)
+	historyStore, err := newHistoryStore(history)
+	if err != nil {
+		log.Fatalf("Failure creating run history store: %v", err)
+	}
+	defer historyStore.Close()
+	schedulerOpts := []scheduler.Option{scheduler.WithHistoryStore(historyStore)}
+	if state.Path != "" {
+		stateStore, err := scheduler.NewFileStateStore(state.Path)
+		if err != nil {
+			log.Fatalf("Failure creating scheduler state store: %v", err)
+		}
+		schedulerOpts = append(schedulerOpts, scheduler.WithStateStore(stateStore))
+	}
+	if err := validatePools(); err != nil {
+		log.Fatalf("Failure validating resource pools: %v", err)
+	}
+	schedulerOpts = append(schedulerOpts, scheduler.WithPools(pools))
+	if queue.MaxConcurrentJobs < 0 {
+		log.Fatalf("Failure validating max_concurrent_jobs: must not be negative")
+	}
+	if err := scheduler.ValidateOverrunPolicy(queue.OverrunPolicy); err != nil {
+		log.Fatalf("Failure validating queue_overrun_policy: %v", err)
+	}
+	schedulerOpts = append(schedulerOpts,
+		scheduler.WithMaxConcurrentJobs(queue.MaxConcurrentJobs),
+		scheduler.WithOverrunPolicy(queue.OverrunPolicy),
+	)
+	if queue.PriorityAging != "" {
+		aging, err := time.ParseDuration(queue.PriorityAging)
+		if err != nil {
+			log.Fatalf("Failure parsing priority_aging value %s: %v", queue.PriorityAging, err)
+		}
+		schedulerOpts = append(schedulerOpts, scheduler.WithPriorityAging(aging))
+	}
+	if clockJumpThreshold != "" {
+		threshold, err := time.ParseDuration(clockJumpThreshold)
+		if err != nil {
+			log.Fatalf("Failure parsing clock_jump_threshold value %s: %v", clockJumpThreshold, err)
+		}
+		if threshold <= 0 {
+			log.Fatalf("Failure validating clock_jump_threshold: must be positive")
+		}
+		schedulerOpts = append(schedulerOpts, scheduler.WithClockJumpThreshold(threshold))
+	}
+	if metrics.Enabled {
+		// Runs, skipped by scheduler, don't call the job command, count them with the skipped status here
+		schedulerOpts = append(schedulerOpts, scheduler.WithSkipHandler(func(jobID string, run job.RunInfo, err error) {
+			metricJobsFinished.WithLabelValues(jobID, "skipped").Inc()
+		}))
+	}
+	sr = scheduler.New(log, schedulerOpts...)
+	if err := validateDependencies(); err != nil {
+		log.Fatalf("Failure validating jobs dependencies: %v", err)
+	}
+	if err := validateTriggers(); err != nil {
+		log.Fatalf("Failure validating jobs triggers: %v", err)
+	}
+	if err := validateStartable(); err != nil {
+		log.Fatalf("Failure validating jobs schedules: %v", err)
+	}
 	for _, jobConfig := range jobConfigs {
-		schedule := []job.Schedule{}
-		for _, scheduleSpec := range jobConfig.ScheduleSpec {
-			if s, err := job.NewSchedule(scheduleSpec); err != nil {
-				log.Fatalf("Failure parsing schedule for job %v: %v", jobConfig.ID, err)
-			} else {
-				schedule = append(schedule, s)
-			}
+		schedule, err := newSchedule(jobConfig)
+		if err != nil {
+			log.Fatalf("Failure parsing schedule for job %v: %v", jobConfig.ID, err)
+		}
+		opts, err := newJobOptions(jobConfig)
+		if err != nil {
+			log.Fatalf("Failure parsing options for job %v: %v", jobConfig.ID, err)
 		}
 		command := createCommand(jobConfig)
-		j, err := job.New(jobConfig.ID, command, schedule)
+		j, err := job.New(jobConfig.ID, command, schedule, opts...)
 		if err != nil {
 			log.Fatalf("Failure creating new job %+v\nError: %v", j, err)
 		}
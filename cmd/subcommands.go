@@ -3,9 +3,11 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/Tarick/tscheduler/pkg/job"
+	"github.com/Tarick/tscheduler/pkg/scheduler"
 
 	"github.com/spf13/cobra"
 )
@@ -15,9 +17,10 @@ var (
 	buildVer   string
 	buildTime  string
 	versionCmd = &cobra.Command{
-		Use:   "version",
-		Short: "Print the version number and build date",
-		Long:  `Version information and build date`,
+		Use:         "version",
+		Short:       "Print the version number and build date",
+		Long:        `Version information and build date`,
+		Annotations: map[string]string{noConfigAnnotation: "true"},
 		Run: func(cmd *cobra.Command, args []string) {
 			fmt.Println("Tscheduler ", buildVer, "-", buildTime)
 		},
@@ -39,6 +42,36 @@ var (
 			printParsedJobs()
 		},
 	}
+	lintCmd = &cobra.Command{
+		Use:   "lint",
+		Short: "Checks jobs schedules for problems",
+		Long:  `Reports schedules which never fire, expire soon, fire at the same time or more often than job timeout. Exits with error if any schedule never fires.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			lintJobs()
+		},
+	}
+	nextRuns int
+	nextCmd  = &cobra.Command{
+		Use:   "next \"<schedule text>\"",
+		Short: "Prints next runs of schedule text",
+		Long:  `Parses english schedule text, e.g. "every weekday at 9:30 in Europe/Kyiv", and prints its next runs.`,
+		Args:  cobra.MinimumNArgs(1),
+		// Schedule text is checked without config
+		Annotations: map[string]string{noConfigAnnotation: "true"},
+		Run: func(cmd *cobra.Command, args []string) {
+			printTextRuns(strings.Join(args, " "), nextRuns)
+		},
+	}
+	exportFormat string
+	exportDir    string
+	exportCmd    = &cobra.Command{
+		Use:   "export",
+		Short: "Exports jobs to crontab or systemd timers",
+		Long:  `Converts configured jobs to crontab lines or systemd .timer/.service unit pairs, warns about schedules which can't be represented exactly.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			exportJobs(exportFormat, exportDir)
+		},
+	}
 	resumeCmd = &cobra.Command{
 		Use:   "resume",
 		Short: "Resumes scheduling",
@@ -63,6 +96,24 @@ var (
 			getStatus()
 		},
 	}
+	workflowsCmd = &cobra.Command{
+		Use:   "workflows",
+		Short: "Workflows returns running and the last finished workflow runs",
+		Long:  `Workflows prints runs of jobs with dependencies and states of their jobs.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			getWorkflows()
+		},
+	}
+	runJobScheduled string
+	runJobCmd       = &cobra.Command{
+		Use:   "run-job <job id>",
+		Short: "Starts the job run right away",
+		Long:  `Starts the job run outside of its schedule by calling /jobs/<id>/run on management interface. The run follows the job concurrency policy, locks and resources.`,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			runJob(args[0], runJobScheduled)
+		},
+	}
 	shutdownCmd = &cobra.Command{
 		Use:   "shutdown",
 		Short: "Gracefully stops scheduler and causes program to exit",
@@ -77,33 +128,75 @@ func init() {
 	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(parseCmd)
+	rootCmd.AddCommand(lintCmd)
+	nextCmd.Flags().IntVarP(&nextRuns, "count", "n", 5, "number of runs to print")
+	rootCmd.AddCommand(nextCmd)
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "crontab", "export format: crontab or systemd")
+	exportCmd.Flags().StringVarP(&exportDir, "output-dir", "o", "", "directory to write files to, stdout by default")
+	rootCmd.AddCommand(exportCmd)
 	rootCmd.AddCommand(pauseCmd)
 	rootCmd.AddCommand(resumeCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(workflowsCmd)
+	runJobCmd.Flags().StringVar(&runJobScheduled, "scheduled", "", "scheduled time of the run in RFC3339 format, e.g. to rerun the past schedule, now by default")
+	rootCmd.AddCommand(runJobCmd)
 	rootCmd.AddCommand(shutdownCmd)
 }
 
 func printParsedJobs() {
 	runs := 5
+	if err := validateDependencies(); err != nil {
+		fmt.Println("Failure validating jobs dependencies: ", err)
+		os.Exit(1)
+	}
+	if err := validateTriggers(); err != nil {
+		fmt.Println("Failure validating jobs triggers: ", err)
+		os.Exit(1)
+	}
+	if err := validateStartable(); err != nil {
+		fmt.Println("Failure validating jobs schedules: ", err)
+		os.Exit(1)
+	}
+	if err := validatePools(); err != nil {
+		fmt.Println("Failure validating resource pools: ", err)
+		os.Exit(1)
+	}
 	fmt.Printf("Jobs next %d scheduled runs:\n", runs)
 	for _, jobConfig := range jobConfigs {
 		command := createCommand(jobConfig)
-		schedule := []job.Schedule{}
-		for _, scheduleSpec := range jobConfig.ScheduleSpec {
-			if s, err := job.NewSchedule(scheduleSpec); err != nil {
-				fmt.Printf("Failure creating schedule for job %v: %v \n", jobConfig.ID, err)
-				os.Exit(1)
-			} else {
-				schedule = append(schedule, s)
-			}
+		schedule, err := newSchedule(jobConfig)
+		if err != nil {
+			fmt.Printf("Failure creating schedule for job %v: %v \n", jobConfig.ID, err)
+			os.Exit(1)
 		}
-		j, err := job.New(jobConfig.ID, command, schedule)
+		opts, err := newJobOptions(jobConfig)
+		if err != nil {
+			fmt.Printf("Failure parsing options for job %v: %v \n", jobConfig.ID, err)
+			os.Exit(1)
+		}
+		j, err := job.New(jobConfig.ID, command, schedule, opts...)
 		if err != nil {
 			fmt.Println("Failure creating job: ", err)
 			continue
 		}
 		t := time.Now()
 		fmt.Printf("\n %v\n", j)
+		if len(j.DependsOn()) != 0 {
+			fmt.Printf("Runs after all of %v have succeeded\n", j.DependsOn())
+			continue
+		}
+		if !j.Scheduled() {
+			continue
+		}
+		if j.FixedDelay() != 0 {
+			t, err = j.Next(t)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
+			fmt.Printf("First run for the Job: %v, then %v after the previous run finishes\n", t, j.FixedDelay())
+			continue
+		}
 		for i := 1; i <= runs; i++ {
 			t, err = j.Next(t)
 			if err != nil {
@@ -115,3 +208,95 @@ func printParsedJobs() {
 		}
 	}
 }
+
+func lintJobs() {
+	failed := false
+	now := time.Now()
+	if err := validateDependencies(); err != nil {
+		fmt.Printf("%s: %v\n", job.LintError, err)
+		failed = true
+	}
+	if err := validateTriggers(); err != nil {
+		fmt.Printf("%s: %v\n", job.LintError, err)
+		failed = true
+	}
+	if err := validatePools(); err != nil {
+		fmt.Printf("%s: %v\n", job.LintError, err)
+		failed = true
+	}
+	if err := scheduler.ValidateOverrunPolicy(queue.OverrunPolicy); err != nil {
+		fmt.Printf("%s: %v\n", job.LintError, err)
+		failed = true
+	}
+	triggered := make(map[string]bool)
+	for _, ids := range jobTriggers() {
+		for _, id := range ids {
+			triggered[id] = true
+		}
+	}
+	for _, jobConfig := range jobConfigs {
+		// Fixed delay, dependent and triggered jobs don't need schedule
+		if len(jobConfig.ScheduleSpec) == 0 && (jobConfig.FixedDelay != "" || len(jobConfig.DependsOn) != 0 || triggered[jobConfig.ID]) {
+			continue
+		}
+		var issues []string
+		schedule := []job.Schedule{}
+		for i, scheduleSpec := range jobConfig.ScheduleSpec {
+			s, err := job.NewSchedule(scheduleSpec)
+			if err != nil {
+				issues = append(issues, fmt.Sprintf("%s: schedule #%d %v", job.LintError, i+1, err))
+				failed = true
+				continue
+			}
+			schedule = append(schedule, s)
+		}
+		var timeout time.Duration
+		if jobConfig.Timeout != "" {
+			var err error
+			timeout, err = time.ParseDuration(jobConfig.Timeout)
+			if err != nil {
+				issues = append(issues, fmt.Sprintf("%s: failure parsing timeout value %s: %v", job.LintError, jobConfig.Timeout, err))
+				failed = true
+			}
+		}
+		// Schedules are linted only if all of them are parsed, otherwise indexes are misleading
+		if len(schedule) == len(jobConfig.ScheduleSpec) {
+			for _, issue := range job.Lint(schedule, timeout, now) {
+				if issue.Severity == job.LintError {
+					failed = true
+				}
+				issues = append(issues, issue.String())
+			}
+		}
+		if len(issues) == 0 {
+			fmt.Printf("%v: OK\n", jobConfig.ID)
+			continue
+		}
+		fmt.Printf("%v:\n", jobConfig.ID)
+		for _, issue := range issues {
+			fmt.Printf("  %s\n", issue)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func printTextRuns(text string, runs int) {
+	s, err := job.NewSchedule(job.ScheduleSpec{Text: text})
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Printf("Schedule: %v\n", s)
+	t := time.Now()
+	for i := 1; i <= runs; i++ {
+		t, err = s.Next(t)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		fmt.Printf("%d run: %v\n", i, t)
+		t = t.Add(1 * time.Second)
+	}
+}
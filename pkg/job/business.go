@@ -0,0 +1,201 @@
+package job
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CalendarSpec defines business calendar.
+// Weekend is weekday spec of non working days, by default "0,6" - Sunday and Saturday.
+// Holidays are dates in "2006-01-02" format, or "01-02" for holidays repeating every year.
+type CalendarSpec struct {
+	Weekend  string
+	Holidays []string
+}
+
+// Calendar is parsed CalendarSpec, which answers if the day is a business day
+type Calendar struct {
+	weekend  [7]bool
+	holidays map[string]struct{}
+}
+
+// NewCalendar creates business calendar from spec
+func NewCalendar(spec CalendarSpec) (*Calendar, error) {
+	if len(spec.Weekend) == 0 {
+		spec.Weekend = "0,6"
+	}
+	weekend, err := parseTimeSpec(spec.Weekend, 0, 6)
+	if err != nil {
+		return nil, fmt.Errorf("parse error of Weekend spec %s: %w", spec.Weekend, err)
+	}
+	c := &Calendar{holidays: make(map[string]struct{}, len(spec.Holidays))}
+	for _, d := range weekend {
+		c.weekend[d] = true
+	}
+	for _, h := range spec.Holidays {
+		h = strings.TrimSpace(h)
+		layout := "2006-01-02"
+		if len(h) == len("01-02") {
+			layout = "01-02"
+		}
+		if _, err := time.Parse(layout, h); err != nil {
+			return nil, fmt.Errorf("parse error of holiday %s: %w", h, err)
+		}
+		c.holidays[h] = struct{}{}
+	}
+	return c, nil
+}
+
+// IsBusinessDay reports if the date of t (in its location) is neither weekend nor holiday
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	if c.weekend[t.Weekday()] {
+		return false
+	}
+	if _, ok := c.holidays[t.Format("2006-01-02")]; ok {
+		return false
+	}
+	_, ok := c.holidays[t.Format("01-02")]
+	return !ok
+}
+
+// BusinessDaySpec defines schedule on Nth business day of a month.
+// Day 1 is the first business day of month, negative Day counts from the month end: -1 is the last business day, -2 is the one before it.
+// Calendar is the name of the shared calendar, resolved with ResolveCalendar, if empty - the calendar is created from Weekend and Holidays.
+// Hour and Minute must be specified, Second by default is 0, Location by default is Local.
+type BusinessDaySpec struct {
+	Day                            int
+	Calendar                       string
+	Weekend                        string
+	Holidays                       []string
+	Hour, Minute, Second, Location string
+	// calendar is the shared calendar, named by Calendar
+	calendar *Calendar
+}
+
+// ResolveCalendar attaches the calendar, named by Calendar, from calendars by name. Spec without Calendar is left as is.
+func (s *BusinessDaySpec) ResolveCalendar(calendars map[string]*Calendar) error {
+	if len(s.Calendar) == 0 {
+		return nil
+	}
+	c, ok := calendars[s.Calendar]
+	if !ok {
+		return fmt.Errorf("unknown calendar %s", s.Calendar)
+	}
+	s.calendar = c
+	return nil
+}
+
+func (s BusinessDaySpec) String() string {
+	return fmt.Sprintf("\nBusiness day: %v\nCalendar: %v\nHours: %v\nMinutes: %v\nSeconds: %v\nLocation: %v\n",
+		s.Day, s.Calendar, s.Hour, s.Minute, s.Second, s.Location)
+}
+
+// businessDayRule is parsed BusinessDaySpec
+type businessDayRule struct {
+	day                  int
+	calendar             *Calendar
+	hour, minute, second []int
+	location             *time.Location
+}
+
+// NewBusinessDaySchedule creates Schedule, which fires on Nth business day of every month
+func NewBusinessDaySchedule(spec BusinessDaySpec) (s Schedule, err error) {
+	r := businessDayRule{day: spec.Day}
+	if spec.Day == 0 || spec.Day > 23 || spec.Day < -23 {
+		return Schedule{}, fmt.Errorf("business day %d must be in range 1..23 or -23..-1", spec.Day)
+	}
+	if len(spec.Calendar) != 0 {
+		r.calendar = spec.calendar
+		if r.calendar == nil {
+			err = fmt.Errorf("calendar %s is not resolved, see BusinessDaySpec.ResolveCalendar", spec.Calendar)
+		}
+	} else {
+		r.calendar, err = NewCalendar(CalendarSpec{Weekend: spec.Weekend, Holidays: spec.Holidays})
+	}
+	if err != nil {
+		return Schedule{}, err
+	}
+	if len(spec.Hour) == 0 || len(spec.Minute) == 0 {
+		return Schedule{}, fmt.Errorf("parse error: business day schedule hour and minute must be specified")
+	}
+	r.hour, err = parseTimeSpec(spec.Hour, 0, 23)
+	if err != nil {
+		return Schedule{}, fmt.Errorf("parse error of Hour spec %s: %w", spec.Hour, err)
+	}
+	r.minute, err = parseTimeSpec(spec.Minute, 0, 59)
+	if err != nil {
+		return Schedule{}, fmt.Errorf("parse error of Minute spec %s: %w", spec.Minute, err)
+	}
+	if len(spec.Second) == 0 {
+		spec.Second = "0"
+	}
+	r.second, err = parseTimeSpec(spec.Second, 0, 59)
+	if err != nil {
+		return Schedule{}, fmt.Errorf("parse error of Seconds spec %s: %w", spec.Second, err)
+	}
+	if len(spec.Location) == 0 {
+		spec.Location = "Local"
+	}
+	r.location, err = time.LoadLocation(spec.Location)
+	if err != nil {
+		return Schedule{}, fmt.Errorf("parse error of Location spec %s: %w", spec.Location, err)
+	}
+	return Schedule{rule: r, location: r.location}, nil
+}
+
+func (r businessDayRule) String() string {
+	return fmt.Sprintf("\nBusiness day: %v\nHours: %v\nMinutes: %v\nSeconds: %v\nLocation: %v\n",
+		r.day, r.hour, r.minute, r.second, r.location)
+}
+
+// next returns the first time on the matching business day, which is not before t
+func (r businessDayRule) next(t time.Time) (time.Time, error) {
+	t = t.Round(time.Second).In(r.location)
+	month := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, r.location)
+	for i := 0; i < MaxYearsAhead*12; i++ {
+		day, ok := r.dayOf(month.AddDate(0, i, 0))
+		if !ok || day.Before(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, r.location)) {
+			continue
+		}
+		if next, ok := r.timeOn(day, t); ok {
+			return next, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("couldn't find next business day run time")
+}
+
+// dayOf returns matching business day of the month, false if month doesn't have enough business days
+func (r businessDayRule) dayOf(month time.Time) (time.Time, bool) {
+	var days []time.Time
+	for d := month; d.Month() == month.Month(); d = d.AddDate(0, 0, 1) {
+		if r.calendar.IsBusinessDay(d) {
+			days = append(days, d)
+		}
+	}
+	i := r.day - 1
+	if r.day < 0 {
+		i = len(days) + r.day
+	}
+	if i < 0 || i >= len(days) {
+		return time.Time{}, false
+	}
+	return days[i], true
+}
+
+// timeOn returns the earliest time of the day, which is not before t
+func (r businessDayRule) timeOn(day, t time.Time) (time.Time, bool) {
+	for _, h := range r.hour {
+		for _, m := range r.minute {
+			// seconds are sorted, find the first one not before t
+			i := sort.Search(len(r.second), func(i int) bool {
+				return !time.Date(day.Year(), day.Month(), day.Day(), h, m, r.second[i], 0, r.location).Before(t)
+			})
+			if i < len(r.second) {
+				return time.Date(day.Year(), day.Month(), day.Day(), h, m, r.second[i], 0, r.location), true
+			}
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,75 @@
+package job
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBusinessDayNext(t *testing.T) {
+	c, err := NewCalendar(CalendarSpec{Holidays: []string{"2021-03-02", "01-01"}})
+	if err != nil {
+		t.Fatalf("Error creating calendar: %v", err)
+	}
+	calendars := map[string]*Calendar{"test": c}
+	tests := []struct {
+		spec     BusinessDaySpec
+		currTime time.Time
+		expected time.Time
+	}{
+		// 1st is Monday, 2nd is holiday
+		{BusinessDaySpec{Day: 3, Calendar: "test", Hour: "9", Minute: "0", Location: "UTC"},
+			time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 3, 4, 9, 0, 0, 0, time.UTC)},
+		// Already passed this month
+		{BusinessDaySpec{Day: 3, Calendar: "test", Hour: "9", Minute: "0", Location: "UTC"},
+			time.Date(2021, 3, 4, 9, 0, 1, 0, time.UTC),
+			time.Date(2021, 4, 5, 9, 0, 0, 0, time.UTC)},
+		// 31st is Wednesday, the day before it
+		{BusinessDaySpec{Day: -2, Hour: "18", Minute: "30", Location: "UTC"},
+			time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 3, 30, 18, 30, 0, 0, time.UTC)},
+		// 1st is holiday, 2nd and 3rd are weekend
+		{BusinessDaySpec{Day: 1, Calendar: "test", Hour: "8, 12", Minute: "0", Location: "UTC"},
+			time.Date(2020, 12, 31, 23, 0, 0, 0, time.UTC),
+			time.Date(2021, 1, 4, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		if err := test.spec.ResolveCalendar(calendars); err != nil {
+			t.Errorf("Error resolving calendar: %v", err)
+			continue
+		}
+		s, err := NewSchedule(ScheduleSpec{BusinessDay: &test.spec})
+		if err != nil {
+			t.Errorf("Error creating business day schedule: %v", err)
+			continue
+		}
+		next, err := s.Next(test.currTime)
+		if err != nil {
+			t.Errorf("Error getting next business day: %v", err)
+			continue
+		}
+		if !next.Equal(test.expected) {
+			t.Errorf("Test for business day %d failed!\nEXPECTED: %v\nNEW: %v\n", test.spec.Day, test.expected, next)
+		}
+	}
+}
+
+func TestResolveCalendar(t *testing.T) {
+	spec := BusinessDaySpec{Day: 1, Calendar: "accounting", Hour: "9", Minute: "0"}
+	if _, err := NewSchedule(ScheduleSpec{BusinessDay: &spec}); err == nil {
+		t.Error("Expected error creating schedule with unresolved calendar")
+	}
+	if err := spec.ResolveCalendar(map[string]*Calendar{}); err == nil {
+		t.Error("Expected error resolving unknown calendar")
+	}
+	c, err := NewCalendar(CalendarSpec{})
+	if err != nil {
+		t.Fatalf("Error creating calendar: %v", err)
+	}
+	if err := spec.ResolveCalendar(map[string]*Calendar{"accounting": c}); err != nil {
+		t.Fatalf("Error resolving calendar: %v", err)
+	}
+	if _, err := NewSchedule(ScheduleSpec{BusinessDay: &spec}); err != nil {
+		t.Errorf("Error creating schedule with resolved calendar: %v", err)
+	}
+}
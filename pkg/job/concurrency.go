@@ -0,0 +1,77 @@
+package job
+
+import "fmt"
+
+// Concurrency policies define what to do with the run, which exceeds the maximum number of simultaneous runs of the job
+const (
+	// ConcurrencySkip doesn't start the run
+	ConcurrencySkip = "skip"
+	// ConcurrencyQueue starts the run after one of the running ones finishes, up to MaxQueued runs wait
+	ConcurrencyQueue = "queue"
+	// ConcurrencyReplace kills the running ones and starts the run
+	ConcurrencyReplace = "replace"
+)
+
+// DefaultConcurrencyMaxQueued caps the number of runs, waiting with ConcurrencyQueue policy
+const DefaultConcurrencyMaxQueued = 10
+
+// ConcurrencyPolicy limits simultaneous runs of the job. Default is one run at a time, overlapping runs are skipped.
+type ConcurrencyPolicy struct {
+	// Max is the maximum number of simultaneous runs, 0 means no limit
+	Max    int
+	Policy string
+	// MaxQueued is the maximum number of waiting runs for ConcurrencyQueue, 0 means DefaultConcurrencyMaxQueued
+	MaxQueued int
+}
+
+// Validate checks the policy name and its settings
+func (p ConcurrencyPolicy) Validate() error {
+	if p.Max < 0 {
+		return fmt.Errorf("concurrency max must not be negative")
+	}
+	switch p.Policy {
+	case "", ConcurrencySkip, ConcurrencyReplace:
+	case ConcurrencyQueue:
+		if p.MaxQueued < 0 {
+			return fmt.Errorf("concurrency max queued must not be negative")
+		}
+	default:
+		return fmt.Errorf("unknown concurrency policy %s, must be one of %s, %s, %s",
+			p.Policy, ConcurrencySkip, ConcurrencyQueue, ConcurrencyReplace)
+	}
+	return nil
+}
+
+func (p ConcurrencyPolicy) String() string {
+	if p.Max == 0 {
+		return "unlimited"
+	}
+	switch p.Policy {
+	case "":
+		return fmt.Sprintf("max %d, %s", p.Max, ConcurrencySkip)
+	case ConcurrencyQueue:
+		return fmt.Sprintf("max %d, %s (max %d)", p.Max, p.Policy, p.MaxQueuedRuns())
+	}
+	return fmt.Sprintf("max %d, %s", p.Max, p.Policy)
+}
+
+// MaxQueuedRuns returns the maximum number of waiting runs for ConcurrencyQueue policy
+func (p ConcurrencyPolicy) MaxQueuedRuns() int {
+	if p.MaxQueued == 0 {
+		return DefaultConcurrencyMaxQueued
+	}
+	return p.MaxQueued
+}
+
+// WithConcurrencyPolicy sets the limit of simultaneous runs of the job, which is one run with ConcurrencySkip policy
+// by default. Zero ConcurrencyPolicy means no limit.
+func WithConcurrencyPolicy(p ConcurrencyPolicy) Option {
+	return func(j *Job) {
+		j.concurrency = p
+	}
+}
+
+// ConcurrencyPolicy returns the job concurrency policy
+func (j *Job) ConcurrencyPolicy() ConcurrencyPolicy {
+	return j.concurrency
+}
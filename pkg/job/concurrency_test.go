@@ -0,0 +1,30 @@
+package job
+
+import (
+	"testing"
+)
+
+func TestConcurrencyPolicy(t *testing.T) {
+	testData := []struct {
+		policy   ConcurrencyPolicy
+		expected string
+		valid    bool
+	}{
+		{ConcurrencyPolicy{Max: 1}, "max 1, skip", true},
+		{ConcurrencyPolicy{}, "unlimited", true},
+		{ConcurrencyPolicy{Max: 2, Policy: ConcurrencyQueue}, "max 2, queue (max 10)", true},
+		{ConcurrencyPolicy{Max: 1, Policy: ConcurrencyReplace}, "max 1, replace", true},
+		{ConcurrencyPolicy{Max: -1}, "", false},
+		{ConcurrencyPolicy{Max: 1, Policy: "wait"}, "", false},
+	}
+	for _, d := range testData {
+		err := d.policy.Validate()
+		if (err == nil) != d.valid {
+			t.Errorf("Test for %#v validation failed, got error %v", d.policy, err)
+			continue
+		}
+		if got := d.policy.String(); d.valid && got != d.expected {
+			t.Errorf("Test for %#v failed!\nEXPECTED: \n %v\nNEW: \n %v\n", d.policy, d.expected, got)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package job
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// systemdWeekdays are weekday names in systemd.time, indexed by weekday number
+var systemdWeekdays = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+// Crontab converts schedule to crontab time fields "minute hour day month weekday".
+// Warnings describe schedule parts, which can't be represented exactly.
+// Empty expression means the schedule can't be converted at all.
+func (s Schedule) Crontab() (expr string, warnings []string) {
+	if s.rule != nil {
+		return "", []string{"solar and business day schedules can't be exported to crontab"}
+	}
+	if len(s.lastDay) != 0 || len(s.nearestWeekday) != 0 || len(s.nthWeekday) != 0 {
+		return "", []string{"last day, nearest weekday and nth weekday of month can't be exported to crontab"}
+	}
+	if len(s.second) != 1 || s.second[0] != 0 {
+		warnings = append(warnings, fmt.Sprintf("seconds %v are not supported by crontab, runs at second 0", s.second))
+	}
+	if len(s.year) != 0 {
+		warnings = append(warnings, fmt.Sprintf("years %v are not supported by crontab, runs every year", s.year))
+	}
+	if s.week != nil || s.yearDay != nil {
+		warnings = append(warnings, "week and year day are not supported by crontab, runs every week")
+	}
+	if s.location != nil && s.location.String() != "Local" {
+		warnings = append(warnings, fmt.Sprintf("timezone %v is not supported by crontab, runs in system timezone", s.location))
+	}
+	day, weekday := compressInts(s.day, 1, 31, "-"), compressInts(s.weekday, 0, 6, "-")
+	if day != "*" && weekday != "*" {
+		warnings = append(warnings, "crontab runs when either day or weekday matches, not both")
+	}
+	expr = strings.Join([]string{
+		compressInts(s.minute, 0, 59, "-"),
+		compressInts(s.hour, 0, 23, "-"),
+		day,
+		compressInts(s.month, 1, 12, "-"),
+		weekday,
+	}, " ")
+	return expr, warnings
+}
+
+// OnCalendar converts schedule to systemd.time calendar expressions, several expressions are needed for nth weekdays.
+// Warnings describe schedule parts, which can't be represented exactly.
+// No expressions means the schedule can't be converted at all.
+func (s Schedule) OnCalendar() (exprs []string, warnings []string) {
+	if s.rule != nil {
+		return nil, []string{"solar and business day schedules can't be exported to systemd timer"}
+	}
+	if len(s.nearestWeekday) != 0 {
+		warnings = append(warnings, "nearest weekday is not supported by systemd timer, skipped")
+	}
+	if s.week != nil || s.yearDay != nil {
+		warnings = append(warnings, "week and year day are not supported by systemd timer, runs every week")
+	}
+	year := "*"
+	if len(s.year) != 0 {
+		// Explicit years are never the whole range
+		year = compressInts(s.year, 0, -1, "..")
+	}
+	month := compressInts(s.month, 1, 12, "..")
+	clock := strings.Join([]string{
+		compressInts(s.hour, 0, 23, ".."),
+		compressInts(s.minute, 0, 59, ".."),
+		compressInts(s.second, 0, 59, ".."),
+	}, ":")
+	zone := ""
+	if s.location != nil && s.location.String() != "Local" {
+		zone = " " + s.location.String()
+	}
+	expr := func(weekdays []int, date string) string {
+		if len(weekdays) == 0 || compressInts(weekdays, 0, 6, "..") == "*" {
+			return date + " " + clock + zone
+		}
+		names := make([]string, len(weekdays))
+		for i, w := range weekdays {
+			names[i] = systemdWeekdays[w]
+		}
+		return strings.Join(names, ",") + " " + date + " " + clock + zone
+	}
+	// Plain days and weekdays
+	if s.day != nil && (len(s.nthWeekday) == 0 || len(s.weekday) != 0) {
+		exprs = append(exprs, expr(s.weekday, year+"-"+month+"-"+compressInts(s.day, 1, 31, "..")))
+	}
+	for _, offset := range s.lastDay {
+		exprs = append(exprs, expr(s.weekday, year+"-"+month+"~"+strconv.Itoa(offset+1)))
+	}
+	// Nth weekday is the weekday in the nth week of month, the last one is in the last 7 days
+	if len(s.nthWeekday) != 0 && (len(s.lastDay) != 0 || compressInts(s.day, 1, 31, "..") != "*") {
+		warnings = append(warnings, "nth weekday combined with days is not supported by systemd timer, days are ignored")
+	}
+	for _, nth := range s.nthWeekday {
+		date := fmt.Sprintf("%s-%s-%02d..%02d", year, month, (nth.n-1)*7+1, nth.n*7)
+		if nth.n < 0 {
+			date = year + "-" + month + "~07/1"
+		}
+		exprs = append(exprs, expr([]int{nth.weekday}, date))
+	}
+	return exprs, warnings
+}
+
+// compressInts converts sorted values to "*" if they are the whole min..max range, otherwise to comma separated values and ranges
+func compressInts(values []int, min, max int, rangeSep string) string {
+	if len(values) != 0 && len(values) == max-min+1 && values[0] == min && values[len(values)-1] == max {
+		return "*"
+	}
+	var result []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		switch {
+		case j-i >= 2:
+			result = append(result, strconv.Itoa(values[i])+rangeSep+strconv.Itoa(values[j]))
+		case j-i == 1:
+			result = append(result, strconv.Itoa(values[i]), strconv.Itoa(values[j]))
+		default:
+			result = append(result, strconv.Itoa(values[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(result, ",")
+}
@@ -0,0 +1,47 @@
+package job
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExport(t *testing.T) {
+	tests := []struct {
+		spec             ScheduleSpec
+		crontab          string
+		crontabWarnings  int
+		onCalendar       []string
+		calendarWarnings int
+	}{
+		{ScheduleSpec{Month: "*", Day: "*", Weekday: "1-5", Hour: "9", Minute: "30"},
+			"30 9 * * 1-5", 0, []string{"Mon,Tue,Wed,Thu,Fri *-*-* 9:30:0"}, 0},
+		{ScheduleSpec{Year: "2026-2028", Month: "1,4,7,10", Day: "1", Hour: "0", Minute: "*/15", Second: "30", Location: "UTC"},
+			"0,15,30,45 0 1 1,4,7,10 *", 3, []string{"2026..2028-1,4,7,10-1 0:0,15,30,45:30 UTC"}, 0},
+		{ScheduleSpec{Quartz: "0 15 10 ? * 6#3,6L"},
+			"", 1, []string{"Fri *-*-15..21 10:15:0", "Fri *-*~07/1 10:15:0"}, 0},
+		{ScheduleSpec{Month: "2", Day: "L-2", Hour: "12", Minute: "0", Week: "1/2"},
+			"", 1, []string{"*-2~3 12:0:0"}, 1},
+	}
+	for _, test := range tests {
+		s, err := NewSchedule(test.spec)
+		if err != nil {
+			t.Errorf("Error creating schedule: %v", err)
+			continue
+		}
+		crontab, warnings := s.Crontab()
+		if crontab != test.crontab || len(warnings) != test.crontabWarnings {
+			t.Errorf("Crontab export failed for %v\nEXPECTED: %v, %d warnings\nNEW: %v, %v", test.spec, test.crontab, test.crontabWarnings, crontab, warnings)
+		}
+		onCalendar, warnings := s.OnCalendar()
+		if diff := cmp.Diff(onCalendar, test.onCalendar); diff != "" || len(warnings) != test.calendarWarnings {
+			t.Errorf("OnCalendar export failed for %v\nEXPECTED: %v, %d warnings\nNEW: %v, %v", test.spec, test.onCalendar, test.calendarWarnings, onCalendar, warnings)
+		}
+		// Exported expressions are parsed back
+		for _, expr := range onCalendar {
+			if _, err := ParseOnCalendar(expr); err != nil {
+				t.Errorf("Exported OnCalendar %s is not parsed: %v", expr, err)
+			}
+		}
+	}
+}
@@ -1,15 +1,49 @@
 package job
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"time"
 )
 
 // New creates and returns new job struct.
-func New(id string, command func(), schedule []Schedule) (*Job, error) {
-	j := &Job{}
+// Fixed delay job could have no schedule, then it starts right away.
+// The job, which depends on other jobs, has no schedule and is started after its upstream jobs.
+// Other jobs without schedule are started only when triggered by other jobs.
+// By default the job has at most one run at a time and the run over the limit is skipped,
+// see WithConcurrencyPolicy with zero ConcurrencyPolicy to allow unlimited simultaneous runs.
+func New(id string, command func(ctx context.Context, run RunInfo) error, schedule []Schedule, opts ...Option) (*Job, error) {
+	j := &Job{concurrency: ConcurrencyPolicy{Max: 1}}
 	j.Id, j.execFunc, j.schedule = id, command, schedule
+	for _, opt := range opts {
+		opt(j)
+	}
+	if err := j.misfirePolicy.Validate(); err != nil {
+		return &Job{}, err
+	}
+	if err := j.concurrency.Validate(); err != nil {
+		return &Job{}, err
+	}
+	if err := j.locks.Validate(); err != nil {
+		return &Job{}, err
+	}
+	if err := j.resources.Validate(); err != nil {
+		return &Job{}, err
+	}
+	if err := j.retryPolicy.Validate(); err != nil {
+		return &Job{}, err
+	}
+	if len(j.dependsOn) != 0 {
+		if len(j.schedule) != 0 || j.fixedDelay != 0 {
+			return &Job{}, fmt.Errorf("job, which depends on other jobs, must not have schedule or fixed delay")
+		}
+		return j, nil
+	}
+	if !j.Scheduled() {
+		return j, nil
+	}
 	// Check if we can schedule it at all
 	if _, err := j.Next(time.Now()); err != nil {
 		return &Job{}, err
@@ -17,30 +51,135 @@ func New(id string, command func(), schedule []Schedule) (*Job, error) {
 	return j, nil
 }
 
+// Option configures optional Job settings, passed to New
+type Option func(*Job)
+
+// WithFixedDelay makes the job run the delay after its previous run has finished, instead of the schedule.
+// The schedule is used only for the first run.
+func WithFixedDelay(delay time.Duration) Option {
+	return func(j *Job) {
+		j.fixedDelay = delay
+	}
+}
+
+// ErrSkipped is returned (possibly wrapped) by job command, which decided not to run, e.g. as its previous run is still active
+var ErrSkipped = errors.New("run skipped")
+
+// RunInfo describes a single run of the job, passed to the job command
+type RunInfo struct {
+	// ID is unique run identifier
+	ID string
+	// Scheduled is the time the run was scheduled at
+	Scheduled time.Time
+	// Started is the actual run start time
+	Started time.Time
+	// Attempt is the run attempt number, starting from 1
+	Attempt int
+	// WorkflowID is the ID of the workflow run, the run belongs to, empty if the job has no dependencies
+	WorkflowID string
+	// Trigger is the run, which has triggered this one, empty if the run is not triggered
+	Trigger Trigger
+	// Manual is true for the run, started on demand outside of the job schedule
+	Manual bool
+}
+
+// Trigger describes the finished run, which has triggered the job
+type Trigger struct {
+	JobID  string
+	RunID  string
+	Status string
+}
+
+// Triggers are IDs of jobs, started after the job run has finished
+type Triggers struct {
+	OnSuccess  []string
+	OnFailure  []string
+	OnComplete []string
+}
+
 // Job definition
 type Job struct {
-	Id       string
-	execFunc func()
-	schedule []Schedule
-	nextRun  time.Time
-	lastRun  time.Time
+	Id         string
+	execFunc   func(ctx context.Context, run RunInfo) error
+	schedule   []Schedule
+	nextRun    time.Time
+	lastRun    time.Time
+	fixedDelay time.Duration
+	// misfirePolicy defines which runs, missed while scheduler was down, are started
+	misfirePolicy MisfirePolicy
+	// retryPolicy defines retries of failed runs
+	retryPolicy RetryPolicy
+	// concurrency limits simultaneous runs
+	concurrency ConcurrencyPolicy
+	// locks are shared with other jobs, which must not run simultaneously
+	locks Locks
+	// resources are weights of the run in scheduler resource pools
+	resources Resources
+	// priority orders job runs, higher first
+	priority int
+	// dependsOn are IDs of upstream jobs, which must succeed before the job is started
+	dependsOn []string
+	// triggers are jobs, started after the job run
+	triggers Triggers
 }
 
-// Run starts Job command.
-func (j *Job) Run() {
-	j.execFunc()
+// Run starts Job command and returns its error. Command must stop when ctx is cancelled.
+func (j *Job) Run(ctx context.Context, run RunInfo) error {
+	return j.execFunc(ctx, run)
 }
 
 func (j *Job) String() string {
-	return fmt.Sprintf("ID: %v\nNext run: %v\nLast run: %v", j.Id, j.nextRun, j.lastRun)
+	s := fmt.Sprintf("ID: %v\nNext run: %v\nLast run: %v", j.Id, j.nextRun, j.lastRun)
+	switch {
+	case len(j.dependsOn) != 0:
+		s += fmt.Sprintf("\nDepends on: %v", j.dependsOn)
+	case !j.Scheduled():
+		s += "\nStarted by other jobs only"
+	case j.fixedDelay != 0:
+		s += fmt.Sprintf("\nFixed delay: %v", j.fixedDelay)
+	default:
+		s += fmt.Sprintf("\nMisfire policy: %v", j.misfirePolicy)
+	}
+	s += fmt.Sprintf("\nConcurrency: %v", j.concurrency)
+	if j.priority != 0 {
+		s += fmt.Sprintf("\nPriority: %d", j.priority)
+	}
+	if len(j.locks.Names) != 0 {
+		s += fmt.Sprintf("\nLocks: %v", j.locks)
+	}
+	if len(j.resources.Weights) != 0 {
+		s += fmt.Sprintf("\nResources: %v", j.resources)
+	}
+	if j.retryPolicy.MaxAttempts > 1 {
+		s += fmt.Sprintf("\nRetry: %v", j.retryPolicy)
+	}
+	for _, t := range []struct {
+		name string
+		ids  []string
+	}{
+		{"On success", j.triggers.OnSuccess},
+		{"On failure", j.triggers.OnFailure},
+		{"On complete", j.triggers.OnComplete},
+	} {
+		if len(t.ids) != 0 {
+			s += fmt.Sprintf("\n%s: %v", t.name, t.ids)
+		}
+	}
+	return s
 }
 
 // Getters and Setters for private fields
 func (j *Job) NextRun() time.Time {
 	return j.nextRun
 }
-// SetNextRun updates job nextRun field, which is actually used by Scheduler
+
+// SetNextRun updates job nextRun field, which is actually used by Scheduler.
+// The job without schedule is not scheduled.
 func (j *Job) SetNextRun(t time.Time) (err error) {
+	if !j.Scheduled() {
+		j.nextRun = time.Time{}
+		return nil
+	}
 	t = t.Add(1 * time.Second)
 	j.nextRun, err = j.Next(t)
 	return err
@@ -49,7 +188,63 @@ func (j *Job) LastRun() time.Time {
 	return j.lastRun
 }
 func (j *Job) SetLastRun(t time.Time) {
-	j.lastRun=t
+	j.lastRun = t
+}
+
+// WithPriority sets the job priority, higher priority jobs start first within the same tick and in scheduler queue. Default is 0.
+func WithPriority(priority int) Option {
+	return func(j *Job) {
+		j.priority = priority
+	}
+}
+
+// Priority returns the job priority
+func (j *Job) Priority() int {
+	return j.priority
+}
+
+// WithDependsOn makes the job start after all the upstream jobs with ids have succeeded in the same workflow run
+func WithDependsOn(ids ...string) Option {
+	return func(j *Job) {
+		j.dependsOn = ids
+	}
+}
+
+// WithTriggers sets jobs, started after the job run has succeeded, failed or either of them
+func WithTriggers(t Triggers) Option {
+	return func(j *Job) {
+		j.triggers = t
+	}
+}
+
+// Triggers returns jobs, started after the job run
+func (j *Job) Triggers() Triggers {
+	return j.triggers
+}
+
+// Scheduled reports if the job is started by its schedule or fixed delay, not only by other jobs
+func (j *Job) Scheduled() bool {
+	return len(j.schedule) != 0 || j.fixedDelay != 0
+}
+
+// DependsOn returns IDs of upstream jobs
+func (j *Job) DependsOn() []string {
+	return j.dependsOn
+}
+
+// FixedDelay returns delay between the job run completion and its next run, 0 if the job runs on schedule
+func (j *Job) FixedDelay() time.Duration {
+	return j.fixedDelay
+}
+
+// SetNextRunAfter schedules fixed delay job relative to the time its previous run has finished
+func (j *Job) SetNextRunAfter(finished time.Time) {
+	j.nextRun = finished.Add(j.fixedDelay)
+}
+
+// ResetNextRun unschedules the job, e.g. until its running fixed delay run is finished
+func (j *Job) ResetNextRun() {
+	j.nextRun = time.Time{}
 }
 
 // timepoints implements sort.Interface, which doesn't use reflection as sort.Slice does
@@ -61,6 +256,10 @@ func (t timepoints) Less(i, j int) bool { return t[i].Before(t[j]) }
 
 // Next returns the next time for job to start and error in case it didn't find the time (e.g. all Schedules expired)
 func (j *Job) Next(t time.Time) (time.Time, error) {
+	// Fixed delay job without schedule runs right away
+	if len(j.schedule) == 0 && j.fixedDelay != 0 {
+		return t.Round(time.Second), nil
+	}
 	// nextRuns := []time.Time{}
 	var nextRuns timepoints
 	for _, s := range j.schedule {
@@ -1,6 +1,7 @@
 package job
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -99,7 +100,7 @@ func TestNext(t *testing.T) {
 		s, _ := NewSchedule(scheduleSpec)
 		schedule = append(schedule, s)
 	}
-	job, err := New("TestNew", func() {}, schedule)
+	job, err := New("TestNew", func(context.Context, RunInfo) error { return nil }, schedule)
 	if err != nil {
 		t.Errorf("Error creating new job: %v", err)
 		return
@@ -138,10 +139,43 @@ func BenchmarkNext(b *testing.B) {
 		s, _ := NewSchedule(scheduleSpec)
 		schedule = append(schedule, s)
 	}
-	job, _ := New("TestNew", func() {}, schedule)
+	job, _ := New("TestNew", func(context.Context, RunInfo) error { return nil }, schedule)
 	currTime := time.Date(2020, 03, 1, 0, 0, 0, 0, time.UTC)
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		job.Next(currTime)
 	}
 }
+
+func TestNextWeekYearDay(t *testing.T) {
+	tests := []struct {
+		spec     ScheduleSpec
+		expected time.Time
+	}{
+		// Mondays of odd ISO weeks, 11 Jan is week 2
+		{ScheduleSpec{Month: "*", Day: "*", Weekday: "1", Week: "1/2", Hour: "9", Minute: "0", Location: "UTC"},
+			time.Date(2021, 1, 18, 9, 0, 0, 0, time.UTC)},
+		{ScheduleSpec{Month: "*", Day: "*", YearDay: "100", Hour: "0", Minute: "0", Location: "UTC"},
+			time.Date(2021, 4, 10, 0, 0, 0, 0, time.UTC)},
+		// 2024 is the next leap year
+		{ScheduleSpec{Month: "*", Day: "*", YearDay: "366", Hour: "0", Minute: "0", Location: "UTC"},
+			time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)},
+	}
+	currTime := time.Date(2021, 1, 5, 0, 0, 0, 0, time.UTC)
+	for _, test := range tests {
+		s, err := NewSchedule(test.spec)
+		if err != nil {
+			t.Errorf("Error creating schedule: %v", err)
+			continue
+		}
+		next, err := s.Next(currTime)
+		if err != nil {
+			t.Errorf("Error getting next time: %v", err)
+			continue
+		}
+		if diff := cmp.Diff(next, test.expected); diff != "" {
+			t.Errorf("Test for week %s, year day %s failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n",
+				test.spec.Week, test.spec.YearDay, test.expected, next, diff)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package job
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Lint severities
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// lintSamples is maximum number of fire times per schedule, checked for duplicates and intervals
+const lintSamples = 1000
+
+// LintIssue describes a problem found in job schedules.
+// Schedule is the index of the schedule in the job, -1 if the issue is about the job as a whole.
+type LintIssue struct {
+	Schedule int
+	Severity string
+	Message  string
+}
+
+func (i LintIssue) String() string {
+	if i.Schedule < 0 {
+		return fmt.Sprintf("%s: %s", i.Severity, i.Message)
+	}
+	return fmt.Sprintf("%s: schedule #%d %s", i.Severity, i.Schedule+1, i.Message)
+}
+
+// Lint checks job schedules starting from time t and reports:
+// schedules which never fire, schedules which stop firing within MaxYearsAhead years from t or now, whichever is later,
+// the same fire times in different schedules and intervals between runs, shorter than job timeout (zero timeout disables the check).
+func Lint(schedule []Schedule, timeout time.Duration, t time.Time) (issues []LintIssue) {
+	// Expiry is checked from now as well, as the schedule, which has stopped firing by now, fires from the past time t
+	from := t
+	if now := time.Now(); now.After(from) {
+		from = now
+	}
+	horizon := from.AddDate(MaxYearsAhead, 0, 0)
+	fires := make([][]time.Time, len(schedule))
+	for i := range schedule {
+		s := &schedule[i]
+		first, err := s.Next(t)
+		if err != nil {
+			issues = append(issues, LintIssue{i, LintError, "never fires: " + err.Error()})
+			continue
+		}
+		if _, err := s.Next(horizon); err != nil {
+			issues = append(issues, LintIssue{i, LintWarning,
+				fmt.Sprintf("stops firing before %v, first run is at %v", horizon.Format("2006-01-02"), first)})
+		}
+		fires[i] = sampleFires(s, first)
+	}
+	if len(schedule) == 0 {
+		issues = append(issues, LintIssue{-1, LintError, "job has no schedules"})
+	}
+	issues = append(issues, lintDuplicates(fires)...)
+	if timeout > 0 {
+		issues = append(issues, lintIntervals(fires, timeout)...)
+	}
+	return issues
+}
+
+// sampleFires returns up to lintSamples fire times, starting with first
+func sampleFires(s *Schedule, first time.Time) []time.Time {
+	fires := []time.Time{first}
+	for len(fires) < lintSamples {
+		next, err := s.Next(fires[len(fires)-1].Add(1 * time.Second))
+		if err != nil {
+			break
+		}
+		fires = append(fires, next)
+	}
+	return fires
+}
+
+// sampledUntil returns the time until which all schedules were sampled, zero time if there are no samples
+func sampledUntil(fires [][]time.Time) (until time.Time) {
+	for _, f := range fires {
+		// Exhausted schedule doesn't limit the window
+		if len(f) == 0 || len(f) < lintSamples {
+			continue
+		}
+		if last := f[len(f)-1]; until.IsZero() || last.Before(until) {
+			until = last
+		}
+	}
+	return
+}
+
+// lintDuplicates reports schedules, firing at the same time as the earlier ones
+func lintDuplicates(fires [][]time.Time) (issues []LintIssue) {
+	until := sampledUntil(fires)
+	seen := make(map[int64]int)
+	for i, f := range fires {
+		duplicates := map[int][]time.Time{}
+		for _, fire := range f {
+			if !until.IsZero() && fire.After(until) {
+				break
+			}
+			if prev, ok := seen[fire.Unix()]; ok && prev != i {
+				duplicates[prev] = append(duplicates[prev], fire)
+				continue
+			}
+			seen[fire.Unix()] = i
+		}
+		for prev := 0; prev < i; prev++ {
+			if times, ok := duplicates[prev]; ok {
+				issues = append(issues, LintIssue{i, LintWarning,
+					fmt.Sprintf("fires at the same time as schedule #%d, %d of sampled runs, first at %v", prev+1, len(times), times[0])})
+			}
+		}
+	}
+	return issues
+}
+
+// lintIntervals reports the shortest interval between job runs if it is less than timeout
+func lintIntervals(fires [][]time.Time, timeout time.Duration) (issues []LintIssue) {
+	until := sampledUntil(fires)
+	var all timepoints
+	for _, f := range fires {
+		for _, fire := range f {
+			if !until.IsZero() && fire.After(until) {
+				break
+			}
+			all = append(all, fire)
+		}
+	}
+	sort.Sort(all)
+	var shortest time.Duration
+	var at time.Time
+	for i := 1; i < len(all); i++ {
+		d := all[i].Sub(all[i-1])
+		if d == 0 {
+			// duplicates are reported separately
+			continue
+		}
+		if shortest == 0 || d < shortest {
+			shortest, at = d, all[i-1]
+		}
+	}
+	if shortest != 0 && shortest < timeout {
+		issues = append(issues, LintIssue{-1, LintWarning,
+			fmt.Sprintf("interval between runs %v (at %v) is shorter than timeout %v, runs can overlap or be skipped", shortest, at, timeout)})
+	}
+	return issues
+}
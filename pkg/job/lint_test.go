@@ -0,0 +1,52 @@
+package job
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLint(t *testing.T) {
+	newScheduleSpecs := []ScheduleSpec{
+		// Never fires
+		{Month: "2", Day: "31", Hour: "0", Minute: "0", Location: "UTC"},
+		// Expires
+		{Year: "2021", Month: "*", Day: "1", Hour: "0", Minute: "0", Location: "UTC"},
+		// Duplicates the previous one in 2021
+		{Month: "*", Day: "1", Hour: "0", Minute: "0, 30", Location: "UTC"},
+	}
+	schedule := []Schedule{}
+	for _, scheduleSpec := range newScheduleSpecs {
+		s, err := NewSchedule(scheduleSpec)
+		if err != nil {
+			t.Fatalf("Error creating schedule: %v", err)
+		}
+		schedule = append(schedule, s)
+	}
+	currTime := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	issues := Lint(schedule, time.Hour, currTime)
+	var got []int
+	for _, issue := range issues {
+		got = append(got, issue.Schedule)
+	}
+	expected := []int{0, 1, 2, -1}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("Lint test failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", expected, issues, diff)
+	}
+	if issues[0].Severity != LintError {
+		t.Errorf("Never firing schedule must be an error, got %v", issues[0])
+	}
+}
+
+func TestLintExpiry(t *testing.T) {
+	s, err := NewSchedule(ScheduleSpec{Year: "2021-2026", Month: "4", Day: "1", Hour: "0", Minute: "0", Location: "UTC"})
+	if err != nil {
+		t.Fatalf("Error creating schedule: %v", err)
+	}
+	// The schedule fires within the horizon from 2021, but has already stopped firing by now
+	issues := Lint([]Schedule{s}, 0, time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC))
+	if len(issues) != 1 || issues[0].Severity != LintWarning {
+		t.Errorf("Expired schedule must be reported, got %v", issues)
+	}
+}
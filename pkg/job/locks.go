@@ -0,0 +1,56 @@
+package job
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Lock policies define what to do with the run, which locks are held by other runs
+const (
+	// LockWait starts the run when all its locks are released
+	LockWait = "wait"
+	// LockSkip doesn't start the run
+	LockSkip = "skip"
+)
+
+// Locks are named mutexes, shared between jobs. Runs of jobs with the same lock never run simultaneously.
+type Locks struct {
+	Names []string
+	// Policy is LockWait by default
+	Policy string
+}
+
+// Validate checks the lock names and the policy
+func (l Locks) Validate() error {
+	for _, name := range l.Names {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("lock name must not be empty")
+		}
+	}
+	switch l.Policy {
+	case "", LockWait, LockSkip:
+	default:
+		return fmt.Errorf("unknown lock policy %s, must be one of %s, %s", l.Policy, LockWait, LockSkip)
+	}
+	return nil
+}
+
+func (l Locks) String() string {
+	policy := l.Policy
+	if policy == "" {
+		policy = LockWait
+	}
+	return fmt.Sprintf("%s (%s)", strings.Join(l.Names, ", "), policy)
+}
+
+// WithLocks sets named locks, held by the job runs
+func WithLocks(l Locks) Option {
+	return func(j *Job) {
+		j.locks = l
+	}
+}
+
+// Locks returns the job locks
+func (j *Job) Locks() Locks {
+	return j.locks
+}
@@ -0,0 +1,28 @@
+package job
+
+import (
+	"testing"
+)
+
+func TestLocks(t *testing.T) {
+	testData := []struct {
+		locks    Locks
+		expected string
+		valid    bool
+	}{
+		{Locks{Names: []string{"db"}}, "db (wait)", true},
+		{Locks{Names: []string{"db", "disk"}, Policy: LockSkip}, "db, disk (skip)", true},
+		{Locks{Names: []string{" "}}, "", false},
+		{Locks{Names: []string{"db"}, Policy: "queue"}, "", false},
+	}
+	for _, d := range testData {
+		err := d.locks.Validate()
+		if (err == nil) != d.valid {
+			t.Errorf("Test for %#v validation failed, got error %v", d.locks, err)
+			continue
+		}
+		if got := d.locks.String(); d.valid && got != d.expected {
+			t.Errorf("Test for %#v failed!\nEXPECTED: \n %v\nNEW: \n %v\n", d.locks, d.expected, got)
+		}
+	}
+}
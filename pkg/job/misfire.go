@@ -0,0 +1,133 @@
+package job
+
+import (
+	"fmt"
+	"time"
+)
+
+// Misfire policies define what to do with runs, missed while scheduler was down or paused
+const (
+	// MisfireSkip doesn't start missed runs
+	MisfireSkip = "skip"
+	// MisfireRunOnce starts the latest missed run only
+	MisfireRunOnce = "run-once"
+	// MisfireRunAll starts all missed runs one after another, up to MaxRuns latest ones
+	MisfireRunAll = "run-all-missed"
+	// MisfireRunIfWithinGrace starts the latest missed run if it is late less than Grace
+	MisfireRunIfWithinGrace = "run-if-within-grace"
+)
+
+// DefaultMisfireMaxRuns caps the number of missed runs, started with MisfireRunAll policy
+const DefaultMisfireMaxRuns = 10
+
+// MisfirePolicy defines which missed runs are started after scheduler start
+type MisfirePolicy struct {
+	Policy string
+	// MaxRuns is the maximum number of missed runs for MisfireRunAll, 0 means DefaultMisfireMaxRuns
+	MaxRuns int
+	// Grace is the maximum delay of the missed run for MisfireRunIfWithinGrace
+	Grace time.Duration
+}
+
+// Validate checks the policy name and its settings
+func (p MisfirePolicy) Validate() error {
+	switch p.Policy {
+	case "", MisfireSkip, MisfireRunOnce:
+	case MisfireRunAll:
+		if p.MaxRuns < 0 {
+			return fmt.Errorf("misfire max runs must not be negative")
+		}
+	case MisfireRunIfWithinGrace:
+		if p.Grace <= 0 {
+			return fmt.Errorf("misfire grace period must be positive for %s policy", p.Policy)
+		}
+	default:
+		return fmt.Errorf("unknown misfire policy %s, must be one of %s, %s, %s, %s",
+			p.Policy, MisfireSkip, MisfireRunOnce, MisfireRunAll, MisfireRunIfWithinGrace)
+	}
+	return nil
+}
+
+func (p MisfirePolicy) String() string {
+	switch p.Policy {
+	case "":
+		return MisfireSkip
+	case MisfireRunAll:
+		return fmt.Sprintf("%s (max %d)", p.Policy, p.maxRuns())
+	case MisfireRunIfWithinGrace:
+		return fmt.Sprintf("%s (%v)", p.Policy, p.Grace)
+	}
+	return p.Policy
+}
+
+func (p MisfirePolicy) maxRuns() int {
+	if p.MaxRuns == 0 {
+		return DefaultMisfireMaxRuns
+	}
+	return p.MaxRuns
+}
+
+// WithMisfirePolicy sets the policy for runs, missed while scheduler was down or paused, or by the wall clock jump forward.
+// Default is MisfireSkip.
+func WithMisfirePolicy(p MisfirePolicy) Option {
+	return func(j *Job) {
+		j.misfirePolicy = p
+	}
+}
+
+// MisfirePolicy returns the job misfire policy
+func (j *Job) MisfirePolicy() MisfirePolicy {
+	return j.misfirePolicy
+}
+
+// MissedRuns returns scheduled times in (after, until], which must be started according to the misfire policy, the earliest first.
+// Fixed delay jobs have no missed runs.
+func (j *Job) MissedRuns(after, until time.Time) []time.Time {
+	if j.fixedDelay != 0 {
+		return nil
+	}
+	p := j.misfirePolicy
+	switch p.Policy {
+	case MisfireRunOnce:
+		return j.lastFires(after, until, 1)
+	case MisfireRunAll:
+		return j.lastFires(after, until, p.maxRuns())
+	case MisfireRunIfWithinGrace:
+		if runs := j.lastFires(after, until, 1); len(runs) != 0 && until.Sub(runs[0]) <= p.Grace {
+			return runs
+		}
+	}
+	return nil
+}
+
+// lastFires returns up to n latest fire times in (after, until], the earliest first.
+// The search window grows from until backwards, so long downtime doesn't scan all fire times.
+func (j *Job) lastFires(after, until time.Time, n int) []time.Time {
+	for window := time.Minute; ; window *= 2 {
+		from := until.Add(-window)
+		if !from.After(after) {
+			from = after
+		}
+		fires := j.fires(from, until, n)
+		if len(fires) >= n || from.Equal(after) {
+			return fires
+		}
+	}
+}
+
+// fires returns up to n latest fire times in (from, until], the earliest first
+func (j *Job) fires(from, until time.Time, n int) []time.Time {
+	var result []time.Time
+	t := from.Truncate(time.Second).Add(time.Second)
+	for {
+		next, err := j.Next(t)
+		if err != nil || next.After(until) {
+			return result
+		}
+		result = append(result, next)
+		if len(result) > n {
+			result = result[1:]
+		}
+		t = next.Add(time.Second)
+	}
+}
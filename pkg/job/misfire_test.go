@@ -0,0 +1,57 @@
+package job
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMissedRuns(t *testing.T) {
+	s, err := NewSchedule(ScheduleSpec{Month: "*", Day: "*", Weekday: "*", Hour: "*", Minute: "0", Second: "0", Location: "UTC"})
+	if err != nil {
+		t.Fatalf("Error creating schedule: %v", err)
+	}
+	after := time.Date(2021, 3, 1, 0, 30, 0, 0, time.UTC)
+	until := time.Date(2021, 3, 1, 5, 10, 0, 0, time.UTC)
+	hours := func(hours ...int) (result []time.Time) {
+		for _, h := range hours {
+			result = append(result, time.Date(2021, 3, 1, h, 0, 0, 0, time.UTC))
+		}
+		return
+	}
+	testData := []struct {
+		policy   MisfirePolicy
+		expected []time.Time
+	}{
+		{MisfirePolicy{}, nil},
+		{MisfirePolicy{Policy: MisfireSkip}, nil},
+		{MisfirePolicy{Policy: MisfireRunOnce}, hours(5)},
+		{MisfirePolicy{Policy: MisfireRunAll, MaxRuns: 3}, hours(3, 4, 5)},
+		{MisfirePolicy{Policy: MisfireRunAll}, hours(1, 2, 3, 4, 5)},
+		{MisfirePolicy{Policy: MisfireRunIfWithinGrace, Grace: 15 * time.Minute}, hours(5)},
+		{MisfirePolicy{Policy: MisfireRunIfWithinGrace, Grace: 5 * time.Minute}, nil},
+	}
+	for _, d := range testData {
+		j, err := New("test", func(context.Context, RunInfo) error { return nil }, []Schedule{s}, WithMisfirePolicy(d.policy))
+		if err != nil {
+			t.Fatalf("Error creating job: %v", err)
+		}
+		got := j.MissedRuns(after, until)
+		if diff := cmp.Diff(got, d.expected); diff != "" {
+			t.Errorf("Test for %v failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", d.policy, d.expected, got, diff)
+		}
+	}
+	// Long downtime with the schedule every second
+	s, _ = NewSchedule(ScheduleSpec{Month: "*", Day: "*", Weekday: "*", Hour: "*", Minute: "*", Second: "*", Location: "UTC"})
+	j, _ := New("test", func(context.Context, RunInfo) error { return nil }, []Schedule{s}, WithMisfirePolicy(MisfirePolicy{Policy: MisfireRunAll, MaxRuns: 2}))
+	got := j.MissedRuns(after.AddDate(-1, 0, 0), until)
+	expected := []time.Time{until.Add(-time.Second), until}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("Test for long downtime failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", expected, got, diff)
+	}
+	if _, err := New("test", func(context.Context, RunInfo) error { return nil }, []Schedule{s}, WithMisfirePolicy(MisfirePolicy{Policy: "run"})); err == nil {
+		t.Errorf("Unknown misfire policy must fail")
+	}
+}
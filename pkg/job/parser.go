@@ -41,6 +41,79 @@ func parseYearSpec(spec string) (result []int, err error) {
 	return
 }
 
+// parseDaySpec parses day of month spec, which in addition to parseTimeSpec syntax supports
+// "L" for the last day of month, "L-n" for n days before the last day,
+// "nW" for the nearest to n Monday-Friday day within the month and "LW" for the last Monday-Friday day of month.
+func parseDaySpec(spec string) (days, lastDays, nearestWeekdays []int, err error) {
+	var rest []string
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.Trim(entry, " ")
+		switch {
+		case entry == "L":
+			lastDays = append(lastDays, 0)
+		case entry == "LW":
+			nearestWeekdays = append(nearestWeekdays, 0)
+		case strings.HasPrefix(entry, "L-"):
+			n, err := convertString(entry[2:])
+			if err != nil || n < 0 || n > 30 {
+				return nil, nil, nil, fmt.Errorf("unsupported last day entry '%s', must be L-0..L-30", entry)
+			}
+			lastDays = append(lastDays, n)
+		case strings.HasSuffix(entry, "W"):
+			n, err := convertString(strings.TrimSuffix(entry, "W"))
+			if err != nil || n < 1 || n > 31 {
+				return nil, nil, nil, fmt.Errorf("unsupported nearest weekday entry '%s', must be 1W..31W", entry)
+			}
+			nearestWeekdays = append(nearestWeekdays, n)
+		default:
+			rest = append(rest, entry)
+		}
+	}
+	if len(rest) != 0 {
+		days, err = parseTimeSpec(strings.Join(rest, ","), 1, 31)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	lastDays = sliceRemoveDuplicates(lastDays)
+	sort.Ints(lastDays)
+	return days, lastDays, nearestWeekdays, nil
+}
+
+// parseWeekdaySpec parses weekday spec, which in addition to parseTimeSpec syntax supports
+// "nL" for the last weekday n of month (e.g. 5L - the last Friday) and "n#k" for k-th weekday n of month (e.g. 5#3 - the third Friday).
+func parseWeekdaySpec(spec string) (weekdays []int, nth []nthWeekday, err error) {
+	var rest []string
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.Trim(entry, " ")
+		switch {
+		case strings.HasSuffix(entry, "L") && len(entry) > 1:
+			n, err := convertString(strings.TrimSuffix(entry, "L"))
+			if err != nil || n < 0 || n > 6 {
+				return nil, nil, fmt.Errorf("unsupported last weekday entry '%s', must be 0L..6L", entry)
+			}
+			nth = append(nth, nthWeekday{weekday: n, n: -1})
+		case strings.Contains(entry, "#"):
+			split := strings.Split(entry, "#")
+			n, err1 := convertString(split[0])
+			k, err2 := convertString(split[len(split)-1])
+			if len(split) != 2 || err1 != nil || err2 != nil || n < 0 || n > 6 || k < 1 || k > 5 {
+				return nil, nil, fmt.Errorf("unsupported nth weekday entry '%s', must be 0#1..6#5", entry)
+			}
+			nth = append(nth, nthWeekday{weekday: n, n: k})
+		default:
+			rest = append(rest, entry)
+		}
+	}
+	if len(rest) != 0 {
+		weekdays, err = parseTimeSpec(strings.Join(rest, ","), 0, 6)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return weekdays, nth, nil
+}
+
 func parseTimeSpec(s string, min, max int) (result []int, err error) {
 	ss := strings.Split(s, ",")
 	for _, entry := range ss {
@@ -85,6 +158,13 @@ func parseTimeSpec(s string, min, max int) (result []int, err error) {
 	return
 
 }
+
+// containsInt reports if sorted slice s contains v
+func containsInt(s []int, v int) bool {
+	i := sort.SearchInts(s, v)
+	return i < len(s) && s[i] == v
+}
+
 func sliceRemoveDuplicates(s []int) []int {
 	seen := make(map[int]struct{}, len(s))
 	j := 0
@@ -0,0 +1,176 @@
+package job
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// quartzMonths maps Quartz month names to numbers
+var quartzMonths = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+// quartzWeekdays maps Quartz weekday names to numbers, Quartz counts from Sunday as 1
+var quartzWeekdays = map[string]int{
+	"SUN": 1, "MON": 2, "TUE": 3, "WED": 4, "THU": 5, "FRI": 6, "SAT": 7,
+}
+
+// ParseQuartz converts Quartz scheduler cron expression to ScheduleSpec.
+// The expression has 6 or 7 fields: Seconds Minutes Hours Day-of-month Month Day-of-week [Year].
+// As in Quartz, one of Day-of-month and Day-of-week must be "?".
+// "L", "L-n", "W", "LW" are supported in Day-of-month, "L" and "#" - in Day-of-week, which counts from Sunday as 1.
+func ParseQuartz(expr string) (spec ScheduleSpec, err error) {
+	fields := strings.Fields(strings.ToUpper(expr))
+	if len(fields) != 6 && len(fields) != 7 {
+		return ScheduleSpec{}, fmt.Errorf("quartz expression must have 6 or 7 fields, got %d", len(fields))
+	}
+	if (fields[3] == "?") == (fields[5] == "?") {
+		return ScheduleSpec{}, fmt.Errorf("one of day-of-month and day-of-week must be '?'")
+	}
+	if spec.Second, err = convertQuartzValues(fields[0], 0, 59, nil, 0); err != nil {
+		return ScheduleSpec{}, fmt.Errorf("parse error of seconds %s: %w", fields[0], err)
+	}
+	if spec.Minute, err = convertQuartzValues(fields[1], 0, 59, nil, 0); err != nil {
+		return ScheduleSpec{}, fmt.Errorf("parse error of minutes %s: %w", fields[1], err)
+	}
+	if spec.Hour, err = convertQuartzValues(fields[2], 0, 23, nil, 0); err != nil {
+		return ScheduleSpec{}, fmt.Errorf("parse error of hours %s: %w", fields[2], err)
+	}
+	if spec.Day, err = convertQuartzDays(fields[3]); err != nil {
+		return ScheduleSpec{}, fmt.Errorf("parse error of day-of-month %s: %w", fields[3], err)
+	}
+	if spec.Month, err = convertQuartzValues(fields[4], 1, 12, quartzMonths, 0); err != nil {
+		return ScheduleSpec{}, fmt.Errorf("parse error of month %s: %w", fields[4], err)
+	}
+	if spec.Weekday, err = convertQuartzWeekdays(fields[5]); err != nil {
+		return ScheduleSpec{}, fmt.Errorf("parse error of day-of-week %s: %w", fields[5], err)
+	}
+	spec.Year = "*"
+	if len(fields) == 7 {
+		if spec.Year, err = convertQuartzValues(fields[6], 1970, 2099, nil, 0); err != nil {
+			return ScheduleSpec{}, fmt.Errorf("parse error of year %s: %w", fields[6], err)
+		}
+	}
+	return spec, nil
+}
+
+// convertQuartzDays converts day-of-month field, "L", "L-n", "nW" and "LW" are the same in ScheduleSpec
+func convertQuartzDays(field string) (string, error) {
+	if field == "?" {
+		return "*", nil
+	}
+	var result []string
+	for _, entry := range strings.Split(field, ",") {
+		if strings.HasPrefix(entry, "L") || strings.HasSuffix(entry, "W") {
+			result = append(result, entry)
+			continue
+		}
+		converted, err := convertQuartzValues(entry, 1, 31, nil, 0)
+		if err != nil {
+			return "", err
+		}
+		result = append(result, converted)
+	}
+	return strings.Join(result, ","), nil
+}
+
+// convertQuartzWeekdays converts day-of-week field to ScheduleSpec numbering from Sunday as 0.
+// "L" alone is Saturday, "nL" is the last weekday n of month, "n#k" is k-th weekday n of month.
+func convertQuartzWeekdays(field string) (string, error) {
+	switch field {
+	case "?", "*":
+		return "*", nil
+	case "L":
+		return "6", nil
+	}
+	var result []string
+	for _, entry := range strings.Split(field, ",") {
+		suffix := ""
+		if strings.HasSuffix(entry, "L") {
+			entry, suffix = strings.TrimSuffix(entry, "L"), "L"
+		} else if i := strings.Index(entry, "#"); i != -1 {
+			entry, suffix = entry[:i], entry[i:]
+		}
+		converted, err := convertQuartzValues(entry, 1, 7, quartzWeekdays, -1)
+		if err != nil {
+			return "", err
+		}
+		if suffix != "" && strings.ContainsAny(converted, ",-*/") {
+			return "", fmt.Errorf("'%s' must be used with single weekday", suffix)
+		}
+		result = append(result, converted+suffix)
+	}
+	return strings.Join(result, ","), nil
+}
+
+// convertQuartzValues converts comma separated values, "a-b" ranges and "a/n", "a-b/n" increments in min..max range to ScheduleSpec syntax.
+// Names are replaced with their numbers, offset is added to every number.
+func convertQuartzValues(field string, min, max int, names map[string]int, offset int) (string, error) {
+	if field == "*" {
+		return "*", nil
+	}
+	value := func(s string) (int, error) {
+		if n, ok := names[s]; ok {
+			return n, nil
+		}
+		n, err := convertString(s)
+		if err != nil {
+			return 0, fmt.Errorf("unsupported value '%s'", s)
+		}
+		if n < min || n > max {
+			return 0, fmt.Errorf("value %d must be in range %d..%d", n, min, max)
+		}
+		return n, nil
+	}
+	var result []string
+	for _, entry := range strings.Split(field, ",") {
+		first, last, step := min, max, 1
+		increment := strings.Split(entry, "/")
+		if len(increment) > 2 {
+			return "", fmt.Errorf("unsupported entry '%s'", entry)
+		}
+		if len(increment) == 2 {
+			n, err := convertString(increment[1])
+			if err != nil || n <= 0 {
+				return "", fmt.Errorf("unsupported increment '%s'", entry)
+			}
+			step = n
+		}
+		var err error
+		r := strings.Split(increment[0], "-")
+		switch {
+		case len(r) > 2:
+			return "", fmt.Errorf("unsupported range '%s'", entry)
+		case r[0] == "*":
+		case len(r) == 2:
+			if first, err = value(r[0]); err != nil {
+				return "", err
+			}
+			if last, err = value(r[1]); err != nil {
+				return "", err
+			}
+		default:
+			if first, err = value(r[0]); err != nil {
+				return "", err
+			}
+			// Single value without increment
+			if len(increment) == 1 {
+				last = first
+			}
+		}
+		// Ranges could wrap over max, e.g. FRI-MON
+		if last < first {
+			last += max - min + 1
+		}
+		for i := first; i <= last; i += step {
+			n := i
+			if n > max {
+				n -= max - min + 1
+			}
+			result = append(result, strconv.Itoa(n+offset))
+		}
+	}
+	return strings.Join(result, ","), nil
+}
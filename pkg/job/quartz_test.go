@@ -0,0 +1,121 @@
+package job
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// Examples from Quartz CronTrigger documentation
+func TestQuartzNext(t *testing.T) {
+	tests := []struct {
+		expr     string
+		currTime time.Time
+		expected time.Time
+	}{
+		// Fire at 12pm (noon) every day
+		{"0 0 12 * * ?",
+			time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)},
+		// Fire at 10:15am every day
+		{"0 15 10 ? * *",
+			time.Date(2021, 3, 1, 10, 15, 1, 0, time.UTC),
+			time.Date(2021, 3, 2, 10, 15, 0, 0, time.UTC)},
+		// Fire at 10:15am every day during the year 2005
+		{"0 15 10 * * ? 2005",
+			time.Date(2005, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2005, 1, 1, 10, 15, 0, 0, time.UTC)},
+		// Fire every minute starting at 2pm and ending at 2:59pm, every day
+		{"0 * 14 * * ?",
+			time.Date(2021, 3, 1, 14, 30, 1, 0, time.UTC),
+			time.Date(2021, 3, 1, 14, 31, 0, 0, time.UTC)},
+		// Fire every 5 minutes starting at 2pm and ending at 2:55pm, every day
+		{"0 0/5 14 * * ?",
+			time.Date(2021, 3, 1, 14, 1, 0, 0, time.UTC),
+			time.Date(2021, 3, 1, 14, 5, 0, 0, time.UTC)},
+		// Fire every 5 minutes starting at 2pm and ending at 2:55pm, AND fire every 5 minutes starting at 6pm and ending at 6:55pm
+		{"0 0/5 14,18 * * ?",
+			time.Date(2021, 3, 1, 14, 56, 0, 0, time.UTC),
+			time.Date(2021, 3, 1, 18, 0, 0, 0, time.UTC)},
+		// Fire every minute starting at 2pm and ending at 2:05pm, every day
+		{"0 0-5 14 * * ?",
+			time.Date(2021, 3, 1, 14, 5, 1, 0, time.UTC),
+			time.Date(2021, 3, 2, 14, 0, 0, 0, time.UTC)},
+		// Fire at 2:10pm and at 2:44pm every Wednesday in the month of March
+		{"0 10,44 14 ? 3 WED",
+			time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 3, 3, 14, 10, 0, 0, time.UTC)},
+		// Fire at 10:15am every Monday, Tuesday, Wednesday, Thursday and Friday
+		{"0 15 10 ? * MON-FRI",
+			time.Date(2021, 3, 6, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 3, 8, 10, 15, 0, 0, time.UTC)},
+		// Fire at 10:15am on the 15th day of every month
+		{"0 15 10 15 * ?",
+			time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 3, 15, 10, 15, 0, 0, time.UTC)},
+		// Fire at 10:15am on the last day of every month
+		{"0 15 10 L * ?",
+			time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 2, 28, 10, 15, 0, 0, time.UTC)},
+		// Fire at 10:15am on the 2nd-to-last last day of every month
+		{"0 15 10 L-2 * ?",
+			time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 3, 29, 10, 15, 0, 0, time.UTC)},
+		// Fire at 10:15am on the last Friday of every month
+		{"0 15 10 ? * 6L",
+			time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 3, 26, 10, 15, 0, 0, time.UTC)},
+		// Fire at 10:15am on every last friday of every month during the years 2002, 2003, 2004 and 2005
+		{"0 15 10 ? * 6L 2002-2005",
+			time.Date(2005, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2005, 1, 28, 10, 15, 0, 0, time.UTC)},
+		{"0 15 10 ? * 6L 2026-2028",
+			time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2026, 1, 30, 10, 15, 0, 0, time.UTC)},
+		// Fire at 10:15am on the third Friday of every month
+		{"0 15 10 ? * 6#3",
+			time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 3, 19, 10, 15, 0, 0, time.UTC)},
+		// Fire at 12pm (noon) every 5 days every month, starting on the first day of the month
+		{"0 0 12 1/5 * ?",
+			time.Date(2021, 3, 2, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 3, 6, 12, 0, 0, 0, time.UTC)},
+		// Fire every November 11th at 11:11am
+		{"0 11 11 11 11 ?",
+			time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 11, 11, 11, 11, 0, 0, time.UTC)},
+		// The nearest weekday to the 15th, Saturday 15th goes to Friday
+		{"0 0 12 15W * ?",
+			time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 5, 14, 12, 0, 0, 0, time.UTC)},
+		// Saturday 1st goes to Monday, not to the previous month
+		{"0 0 12 1W * ?",
+			time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 5, 3, 12, 0, 0, 0, time.UTC)},
+		// The last weekday of month, 31st is Saturday
+		{"0 0 12 LW * ?",
+			time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 7, 30, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		s, err := NewSchedule(ScheduleSpec{Quartz: test.expr, Location: "UTC"})
+		if err != nil {
+			t.Errorf("Error creating schedule for %s: %v", test.expr, err)
+			continue
+		}
+		next, err := s.Next(test.currTime)
+		if err != nil {
+			t.Errorf("Error getting next time for %s: %v", test.expr, err)
+			continue
+		}
+		if diff := cmp.Diff(next, test.expected); diff != "" {
+			t.Errorf("Test for %s failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", test.expr, test.expected, next, diff)
+		}
+	}
+	for _, expr := range []string{"0 0 12 * * *", "0 0 12 ? * MON 2021 1", "0 0 25 * * ?", "0 0 12 ? * MON-FRI#2"} {
+		if _, err := ParseQuartz(expr); err == nil {
+			t.Errorf("Expected parse error for %s", expr)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package job
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Resource policies define what to do with the run, which weights don't fit the free capacity of scheduler resource pools
+const (
+	// ResourcesWait starts the run when the pools have enough free capacity
+	ResourcesWait = "wait"
+	// ResourcesSkip doesn't start the run
+	ResourcesSkip = "skip"
+)
+
+// Resources are weights of the job run in scheduler resource pools by pool name
+type Resources struct {
+	Weights map[string]int
+	// Policy is ResourcesWait by default
+	Policy string
+}
+
+// Validate checks the weights and the policy
+func (r Resources) Validate() error {
+	for pool, weight := range r.Weights {
+		if strings.TrimSpace(pool) == "" {
+			return fmt.Errorf("resource pool name must not be empty")
+		}
+		if weight <= 0 {
+			return fmt.Errorf("resource %s weight must be positive", pool)
+		}
+	}
+	switch r.Policy {
+	case "", ResourcesWait, ResourcesSkip:
+	default:
+		return fmt.Errorf("unknown resources policy %s, must be one of %s, %s", r.Policy, ResourcesWait, ResourcesSkip)
+	}
+	return nil
+}
+
+func (r Resources) String() string {
+	pools := make([]string, 0, len(r.Weights))
+	for pool, weight := range r.Weights {
+		pools = append(pools, fmt.Sprintf("%s: %d", pool, weight))
+	}
+	sort.Strings(pools)
+	policy := r.Policy
+	if policy == "" {
+		policy = ResourcesWait
+	}
+	return fmt.Sprintf("%s (%s)", strings.Join(pools, ", "), policy)
+}
+
+// WithResources sets weights of the job run in scheduler resource pools
+func WithResources(r Resources) Option {
+	return func(j *Job) {
+		j.resources = r
+	}
+}
+
+// Resources returns the job resources
+func (j *Job) Resources() Resources {
+	return j.resources
+}
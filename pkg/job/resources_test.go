@@ -0,0 +1,29 @@
+package job
+
+import (
+	"testing"
+)
+
+func TestResources(t *testing.T) {
+	testData := []struct {
+		resources Resources
+		expected  string
+		valid     bool
+	}{
+		{Resources{Weights: map[string]int{"io": 1, "cpu": 2}}, "cpu: 2, io: 1 (wait)", true},
+		{Resources{Weights: map[string]int{"cpu": 1}, Policy: ResourcesSkip}, "cpu: 1 (skip)", true},
+		{Resources{Weights: map[string]int{"cpu": 0}}, "", false},
+		{Resources{Weights: map[string]int{"": 1}}, "", false},
+		{Resources{Weights: map[string]int{"cpu": 1}, Policy: "queue"}, "", false},
+	}
+	for _, d := range testData {
+		err := d.resources.Validate()
+		if (err == nil) != d.valid {
+			t.Errorf("Test for %#v validation failed, got error %v", d.resources, err)
+			continue
+		}
+		if got := d.resources.String(); d.valid && got != d.expected {
+			t.Errorf("Test for %#v failed!\nEXPECTED: \n %v\nNEW: \n %v\n", d.resources, d.expected, got)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// DefaultRetryMultiplier is the delay multiplier after every failed attempt
+const DefaultRetryMultiplier = 2
+
+// RetryPolicy defines retries of failed runs with exponential backoff
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one, 0 or 1 disables retries
+	MaxAttempts int
+	// InitialDelay is the delay before the second attempt
+	InitialDelay time.Duration
+	// Multiplier increases delay after every attempt, 0 means DefaultRetryMultiplier
+	Multiplier float64
+	// MaxDelay limits the delay, 0 means no limit
+	MaxDelay time.Duration
+	// Jitter randomly changes the delay up to this fraction of it, 0..1
+	Jitter float64
+	// ExitCodes limits retries to failures with these exit codes, any failure is retried if empty
+	ExitCodes []int
+}
+
+// Validate checks the policy settings
+func (p RetryPolicy) Validate() error {
+	switch {
+	case p.MaxAttempts < 0:
+		return fmt.Errorf("retry max attempts must not be negative")
+	case p.InitialDelay < 0 || p.MaxDelay < 0:
+		return fmt.Errorf("retry delays must not be negative")
+	case p.Multiplier != 0 && p.Multiplier < 1:
+		return fmt.Errorf("retry multiplier must be at least 1")
+	case p.Jitter < 0 || p.Jitter > 1:
+		return fmt.Errorf("retry jitter must be in range 0..1")
+	}
+	return nil
+}
+
+func (p RetryPolicy) String() string {
+	if p.MaxAttempts <= 1 {
+		return "none"
+	}
+	s := fmt.Sprintf("%d attempts, delay %v x%v", p.MaxAttempts, p.InitialDelay, p.multiplier())
+	if p.MaxDelay != 0 {
+		s += fmt.Sprintf(" up to %v", p.MaxDelay)
+	}
+	if p.Jitter != 0 {
+		s += fmt.Sprintf(", jitter %v", p.Jitter)
+	}
+	if len(p.ExitCodes) != 0 {
+		s += fmt.Sprintf(", exit codes %v", p.ExitCodes)
+	}
+	return s
+}
+
+func (p RetryPolicy) multiplier() float64 {
+	if p.Multiplier == 0 {
+		return DefaultRetryMultiplier
+	}
+	return p.Multiplier
+}
+
+// Retryable reports if the failed attempt must be retried
+func (p RetryPolicy) Retryable(attempt int, err error) bool {
+	if err == nil || errors.Is(err, ErrSkipped) || attempt >= p.MaxAttempts {
+		return false
+	}
+	if len(p.ExitCodes) == 0 {
+		return true
+	}
+	var exitErr interface{ ExitCode() int }
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+	for _, code := range p.ExitCodes {
+		if code == exitErr.ExitCode() {
+			return true
+		}
+	}
+	return false
+}
+
+// Delay returns the delay before the next attempt after the failed one
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	if p.InitialDelay <= 0 {
+		return 0
+	}
+	// Delay of the late attempt without MaxDelay is limited by the longest duration, instead of overflowing it
+	limit := float64(math.MaxInt64)
+	if p.MaxDelay != 0 {
+		limit = float64(p.MaxDelay)
+	}
+	delay := math.Min(float64(p.InitialDelay)*math.Pow(p.multiplier(), float64(attempt-1)), limit)
+	if p.Jitter != 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+	if delay >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(delay)
+}
+
+// WithRetryPolicy sets retries of failed runs
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(j *Job) {
+		j.retryPolicy = p
+	}
+}
+
+// RetryPolicy returns the job retry policy
+func (j *Job) RetryPolicy() RetryPolicy {
+	return j.retryPolicy
+}
@@ -0,0 +1,67 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRetryDelay(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, InitialDelay: time.Second, MaxDelay: 5 * time.Second}
+	var got []time.Duration
+	for attempt := 1; attempt < p.MaxAttempts; attempt++ {
+		got = append(got, p.Delay(attempt))
+	}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("Test for retry delay failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", expected, got, diff)
+	}
+	p = RetryPolicy{MaxAttempts: 2, InitialDelay: 10 * time.Second, Multiplier: 1.5, Jitter: 0.1}
+	for i := 0; i < 100; i++ {
+		if d := p.Delay(2); d < 13500*time.Millisecond || d > 16500*time.Millisecond {
+			t.Fatalf("Delay %v with jitter is out of range", d)
+		}
+	}
+	// Delay of the late attempt is clamped instead of overflowing
+	p = RetryPolicy{MaxAttempts: 1000, InitialDelay: time.Second, Jitter: 0.5}
+	for i := 0; i < 100; i++ {
+		if d := p.Delay(999); d < math.MaxInt64/2 {
+			t.Fatalf("Delay of attempt 999 without max delay must be about %v, got %v", time.Duration(math.MaxInt64), d)
+		}
+	}
+	p.MaxDelay = time.Hour
+	p.Jitter = 0
+	if d := p.Delay(999); d != time.Hour {
+		t.Errorf("Delay of attempt 999 must be max delay %v, got %v", p.MaxDelay, d)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	exitErr := exec.Command("sh", "-c", "exit 3").Run()
+	p := RetryPolicy{MaxAttempts: 3, ExitCodes: []int{3}}
+	testData := []struct {
+		attempt  int
+		err      error
+		expected bool
+	}{
+		{1, nil, false},
+		{1, fmt.Errorf("failed: %w", exitErr), true},
+		{2, exitErr, true},
+		{3, exitErr, false},
+		{1, errors.New("start failed"), false},
+		{1, fmt.Errorf("running: %w", ErrSkipped), false},
+	}
+	for _, d := range testData {
+		if got := p.Retryable(d.attempt, d.err); got != d.expected {
+			t.Errorf("Test for attempt %d error %v failed!\nEXPECTED: \n %v\nNEW: \n %v\n", d.attempt, d.err, d.expected, got)
+		}
+	}
+	if !(RetryPolicy{MaxAttempts: 2}).Retryable(1, errors.New("start failed")) {
+		t.Errorf("Any failure must be retried without exit codes")
+	}
+}
@@ -2,6 +2,7 @@ package job
 
 import (
 	"fmt"
+	"sort"
 	"time"
 )
 
@@ -9,14 +10,39 @@ import (
 const MaxYearsAhead int = 5
 
 // ScheduleSpec defines time patterns to parse.
+// Week is ISO 8601 week number (1-53), YearDay is day of the year (1-366), both are optional and match any by default.
+// Solar or BusinessDay, if set, makes the schedule fire on sun events or business days instead of the time patterns.
+// OnCalendar is systemd.time calendar expression, Quartz is Quartz cron expression and Text is english text (see ParseText),
+// they replace time patterns, Location is used if they don't specify timezone.
 type ScheduleSpec struct {
-	Second, Minute, Hour, Day, Weekday, Month, Year, Location string
+	Second, Minute, Hour, Day, Weekday, Week, YearDay, Month, Year, Location string
+	Solar                                                                    *SolarSpec
+	BusinessDay                                                              *BusinessDaySpec `mapstructure:"business_day"`
+	OnCalendar                                                               string           `mapstructure:"on_calendar"`
+	Quartz                                                                   string
+	Text                                                                     string
 }
 
 // Schedule struct is numeric representation of already parsed ScheduleSpec and is used during scheduling
 type Schedule struct {
 	second, minute, hour, day, weekday, month, year []int
-	location                                        *time.Location
+	// week and yearDay are nil if not restricted
+	week, yearDay []int
+	// lastDay are days counted from the end of month: 0 is the last day, 1 is the day before it
+	lastDay []int
+	// nearestWeekday are days, moved to the nearest Monday-Friday within the month, 0 is the last day
+	nearestWeekday []int
+	// nthWeekday are weekdays, matched by their position in month. Plain weekdays are matched by monthDays then.
+	nthWeekday []nthWeekday
+	location   *time.Location
+	// rule is alternative schedule kind, which is not expressed by time fields. Takes precedence if set.
+	rule scheduleRule
+}
+
+// scheduleRule is implemented by schedule kinds, which compute next run time on their own (e.g. solar events).
+type scheduleRule interface {
+	next(t time.Time) (time.Time, error)
+	String() string
 }
 
 // New creates Schedule with all available variants in numeric form from string based ScheduleSpec
@@ -25,6 +51,40 @@ type Schedule struct {
 // Second by default is 0.
 // Default Timezone (Location) - Local.
 func NewSchedule(spec ScheduleSpec) (s Schedule, err error) {
+	if spec.Solar != nil {
+		return NewSolarSchedule(*spec.Solar)
+	}
+	if spec.BusinessDay != nil {
+		return NewBusinessDaySchedule(*spec.BusinessDay)
+	}
+	if len(spec.OnCalendar) != 0 {
+		calendarSpec, err := ParseOnCalendar(spec.OnCalendar)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("parse error of OnCalendar %s: %w", spec.OnCalendar, err)
+		}
+		if len(calendarSpec.Location) == 0 {
+			calendarSpec.Location = spec.Location
+		}
+		return NewSchedule(calendarSpec)
+	}
+	if len(spec.Quartz) != 0 {
+		quartzSpec, err := ParseQuartz(spec.Quartz)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("parse error of Quartz %s: %w", spec.Quartz, err)
+		}
+		quartzSpec.Location = spec.Location
+		return NewSchedule(quartzSpec)
+	}
+	if len(spec.Text) != 0 {
+		textSpec, err := ParseText(spec.Text)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("parse error of Text '%s': %w", spec.Text, err)
+		}
+		if len(textSpec.Location) == 0 {
+			textSpec.Location = spec.Location
+		}
+		return NewSchedule(textSpec)
+	}
 	// Year
 	if len(spec.Year) == 0 {
 		spec.Year = "*"
@@ -45,12 +105,26 @@ func NewSchedule(spec ScheduleSpec) (s Schedule, err error) {
 	if len(spec.Weekday) == 0 {
 		spec.Weekday = "*"
 	}
-	s.weekday, err = parseTimeSpec(spec.Weekday, 0, 6)
+	s.weekday, s.nthWeekday, err = parseWeekdaySpec(spec.Weekday)
 	if err != nil {
 		return Schedule{}, fmt.Errorf("parse error of Weekday spec %s: %w", spec.Weekday, err)
 	}
+	// Week (ISO 8601 week number)
+	if len(spec.Week) != 0 {
+		s.week, err = parseTimeSpec(spec.Week, 1, 53)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("parse error of Week spec %s: %w", spec.Week, err)
+		}
+	}
+	// YearDay (day of year)
+	if len(spec.YearDay) != 0 {
+		s.yearDay, err = parseTimeSpec(spec.YearDay, 1, 366)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("parse error of YearDay spec %s: %w", spec.YearDay, err)
+		}
+	}
 	// Day (day of month)
-	s.day, err = parseTimeSpec(spec.Day, 1, 31)
+	s.day, s.lastDay, s.nearestWeekday, err = parseDaySpec(spec.Day)
 	if err != nil {
 		return Schedule{}, fmt.Errorf("parse error of Day spec %s: %w", spec.Day, err)
 	}
@@ -85,16 +159,37 @@ func NewSchedule(spec ScheduleSpec) (s Schedule, err error) {
 }
 
 func (s Schedule) String() string {
-	return fmt.Sprintf("\nYears: %v\nMonths: %v\nDays: %v\nWeekdays: %v\nHours: %v\nMinutes: %v\nSeconds: %v\nLocation: %v\n",
-		s.year, s.month, s.day, s.weekday, s.hour, s.minute, s.second, s.location)
+	if s.rule != nil {
+		return s.rule.String()
+	}
+	return fmt.Sprintf("\nYears: %v\nMonths: %v\nDays: %v\nWeekdays: %v\nWeeks: %v\nYear days: %v\nHours: %v\nMinutes: %v\nSeconds: %v\nLocation: %v\n",
+		s.year, s.month, s.day, s.weekday, s.week, s.yearDay, s.hour, s.minute, s.second, s.location)
 }
 func (s ScheduleSpec) String() string {
-	return fmt.Sprintf("\nYears: %v\nMonths: %v\nDays: %v\nWeekdays: %v\nHours: %v\nMinutes: %v\nSeconds: %v\nLocation: %v\n",
-		s.Year, s.Month, s.Day, s.Weekday, s.Hour, s.Minute, s.Second, s.Location)
+	if s.Solar != nil {
+		return s.Solar.String()
+	}
+	if s.BusinessDay != nil {
+		return s.BusinessDay.String()
+	}
+	if len(s.OnCalendar) != 0 {
+		return fmt.Sprintf("\nOnCalendar: %v\nLocation: %v\n", s.OnCalendar, s.Location)
+	}
+	if len(s.Quartz) != 0 {
+		return fmt.Sprintf("\nQuartz: %v\nLocation: %v\n", s.Quartz, s.Location)
+	}
+	if len(s.Text) != 0 {
+		return fmt.Sprintf("\nText: %v\nLocation: %v\n", s.Text, s.Location)
+	}
+	return fmt.Sprintf("\nYears: %v\nMonths: %v\nDays: %v\nWeekdays: %v\nWeeks: %v\nYear days: %v\nHours: %v\nMinutes: %v\nSeconds: %v\nLocation: %v\n",
+		s.Year, s.Month, s.Day, s.Weekday, s.Week, s.YearDay, s.Hour, s.Minute, s.Second, s.Location)
 }
 
 // Next returns schedule next time to run
 func (s *Schedule) Next(t time.Time) (time.Time, error) {
+	if s.rule != nil {
+		return s.rule.next(t)
+	}
 	// TODO: benchmark and optimize this
 	t = t.Round(time.Second).In(s.location)
 	var err error
@@ -117,7 +212,7 @@ WRAP:
 			break
 		}
 		// Couldn't find next date, bail
-		if i == len(s.year)-1 {
+		if i == len(years)-1 {
 			return time.Time{}, fmt.Errorf("couldn't find next run time")
 		}
 	}
@@ -138,7 +233,12 @@ WRAP:
 		}
 	}
 	day = t.Day()
-	for i, entry := range s.day {
+	days := s.monthDays(t.Year(), t.Month())
+	if len(days) == 0 {
+		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
+		goto WRAP
+	}
+	for i, entry := range days {
 		// fmt.Println("Day:", entry, t)
 		if entry == day {
 			break
@@ -152,13 +252,17 @@ WRAP:
 			break
 		}
 		// Jump to start of next month
-		if i == len(s.day)-1 {
+		if i == len(days)-1 {
 			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.location)
 			goto WRAP
 		}
 	}
 	weekday = int(t.Weekday())
 	for i, entry := range s.weekday {
+		// Weekdays are already matched by monthDays
+		if len(s.nthWeekday) != 0 {
+			break
+		}
 		if entry == weekday {
 			break
 		}
@@ -168,6 +272,18 @@ WRAP:
 			goto WRAP
 		}
 	}
+	// ISO week and day of year are optional, jump to start of next day if they don't match
+	if s.week != nil {
+		_, week := t.ISOWeek()
+		if !containsInt(s.week, week) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+			goto WRAP
+		}
+	}
+	if s.yearDay != nil && !containsInt(s.yearDay, t.YearDay()) {
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.location)
+		goto WRAP
+	}
 	hour = t.Hour()
 	for i, entry := range s.hour {
 		if entry == hour {
@@ -215,3 +331,84 @@ WRAP:
 	}
 	return t, err
 }
+
+// nthWeekday is weekday occurrence in month, e.g. the third Friday. Negative n counts from the month end.
+type nthWeekday struct {
+	weekday, n int
+}
+
+// monthDays returns sorted days of month to run on, resolving days counted from the month end, nearest weekdays and nth weekdays
+func (s *Schedule) monthDays(year int, month time.Month) []int {
+	if len(s.lastDay) == 0 && len(s.nearestWeekday) == 0 && len(s.nthWeekday) == 0 {
+		return s.day
+	}
+	// Zero day of the next month is the last day of this one
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	weekdayOf := func(day int) int {
+		return int(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday())
+	}
+	days := make([]int, 0, len(s.day)+len(s.lastDay)+len(s.nearestWeekday))
+	for _, d := range s.day {
+		if d <= last {
+			days = append(days, d)
+		}
+	}
+	for _, offset := range s.lastDay {
+		if last-offset >= 1 {
+			days = append(days, last-offset)
+		}
+	}
+	for _, d := range s.nearestWeekday {
+		if d == 0 {
+			d = last
+		}
+		if d > last {
+			continue
+		}
+		switch weekdayOf(d) {
+		case 6:
+			// Saturday goes to Friday, unless it is the 1st
+			if d == 1 {
+				d += 2
+			} else {
+				d--
+			}
+		case 0:
+			// Sunday goes to Monday, unless it is the last day
+			if d == last {
+				d -= 2
+			} else {
+				d++
+			}
+		}
+		days = append(days, d)
+	}
+	if len(s.nthWeekday) != 0 {
+		matched := days[:0]
+		for _, d := range days {
+			if s.matchWeekday(weekdayOf(d), d, last) {
+				matched = append(matched, d)
+			}
+		}
+		days = matched
+	}
+	days = sliceRemoveDuplicates(days)
+	sort.Ints(days)
+	return days
+}
+
+// matchWeekday reports if the day matches plain weekdays or nth weekdays of month with the last day
+func (s *Schedule) matchWeekday(weekday, day, last int) bool {
+	if containsInt(s.weekday, weekday) {
+		return true
+	}
+	for _, nth := range s.nthWeekday {
+		if nth.weekday != weekday {
+			continue
+		}
+		if (nth.n > 0 && (day-1)/7+1 == nth.n) || (nth.n < 0 && day+7 > last) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package job
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Solar events, supported by SolarSpec
+const (
+	SolarSunrise = "sunrise"
+	SolarSunset  = "sunset"
+	// SolarDawn is the start of the morning civil twilight, sun is 6° below horizon
+	SolarDawn = "dawn"
+	// SolarDusk is the end of the evening civil twilight, sun is 6° below horizon
+	SolarDusk = "dusk"
+)
+
+// Sun altitudes for events, degrees. Sunrise and sunset take into account atmospheric refraction and sun disc size.
+const (
+	sunriseAltitude       = -0.833
+	civilTwilightAltitude = -6.0
+)
+
+// julianUnixEpoch is Julian date of 1970-01-01 00:00 UTC
+const julianUnixEpoch = 2440587.5
+
+// julian2000 is Julian date of 2000-01-01 12:00 UTC (J2000.0 epoch)
+const julian2000 = 2451545.0
+
+// SolarSpec defines schedule, bound to the sun position at given coordinates.
+// Offset is duration string (e.g. "-30m", "1h15m"), added to the event time.
+type SolarSpec struct {
+	Event     string
+	Latitude  float64
+	Longitude float64
+	Offset    string
+}
+
+func (s SolarSpec) String() string {
+	return fmt.Sprintf("\nSolar event: %v\nLatitude: %v\nLongitude: %v\nOffset: %v\n",
+		s.Event, s.Latitude, s.Longitude, s.Offset)
+}
+
+// solarRule is parsed SolarSpec
+type solarRule struct {
+	event     string
+	latitude  float64
+	longitude float64
+	offset    time.Duration
+}
+
+// NewSolarSchedule creates Schedule, which fires at sunrise, sunset or civil twilight with optional offset.
+// Times are computed locally with NOAA sunrise equation, precision is about a minute.
+func NewSolarSchedule(spec SolarSpec) (Schedule, error) {
+	r := solarRule{latitude: spec.Latitude, longitude: spec.Longitude}
+	r.event = strings.ToLower(strings.TrimSpace(spec.Event))
+	switch r.event {
+	case SolarSunrise, SolarSunset, SolarDawn, SolarDusk:
+	default:
+		return Schedule{}, fmt.Errorf("unsupported solar event '%s', must be one of: %s, %s, %s, %s",
+			spec.Event, SolarSunrise, SolarSunset, SolarDawn, SolarDusk)
+	}
+	if spec.Latitude < -90 || spec.Latitude > 90 {
+		return Schedule{}, fmt.Errorf("latitude %v must be in range -90..90", spec.Latitude)
+	}
+	if spec.Longitude < -180 || spec.Longitude > 180 {
+		return Schedule{}, fmt.Errorf("longitude %v must be in range -180..180", spec.Longitude)
+	}
+	if len(spec.Offset) != 0 {
+		offset, err := time.ParseDuration(spec.Offset)
+		if err != nil {
+			return Schedule{}, fmt.Errorf("parse error of solar offset %s: %w", spec.Offset, err)
+		}
+		r.offset = offset
+	}
+	return Schedule{rule: r, location: time.Local}, nil
+}
+
+func (r solarRule) String() string {
+	return fmt.Sprintf("\nSolar event: %v\nLatitude: %v\nLongitude: %v\nOffset: %v\n",
+		r.event, r.latitude, r.longitude, r.offset)
+}
+
+// next returns the first event time (with offset applied) which is not before t.
+// Days without the event (polar day or night) are skipped.
+func (r solarRule) next(t time.Time) (time.Time, error) {
+	t = t.Round(time.Second)
+	// Event could belong to the previous UTC date for the far east or west longitudes
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+	last := day.AddDate(MaxYearsAhead, 0, 0)
+	for ; day.Before(last); day = day.AddDate(0, 0, 1) {
+		event, ok := r.eventOn(day)
+		if !ok {
+			continue
+		}
+		event = event.Add(r.offset).Round(time.Second)
+		if !event.Before(t) {
+			return event.In(t.Location()), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("couldn't find next %s time", r.event)
+}
+
+// eventOn calculates the event for UTC date of day, returns false if the sun doesn't reach the event altitude.
+func (r solarRule) eventOn(day time.Time) (time.Time, bool) {
+	altitude, morning := sunriseAltitude, true
+	switch r.event {
+	case SolarSunset:
+		morning = false
+	case SolarDawn:
+		altitude = civilTwilightAltitude
+	case SolarDusk:
+		altitude, morning = civilTwilightAltitude, false
+	}
+	// Mean solar noon
+	noon := float64(day.Add(12*time.Hour).Unix())/86400 + julianUnixEpoch
+	n := math.Round(noon - julian2000 + 0.0008)
+	meanNoon := n - r.longitude/360
+	// Solar mean anomaly
+	m := math.Mod(357.5291+0.98560028*meanNoon, 360)
+	mRad := radians(m)
+	// Equation of the center
+	c := 1.9148*math.Sin(mRad) + 0.0200*math.Sin(2*mRad) + 0.0003*math.Sin(3*mRad)
+	// Ecliptic longitude
+	lambda := radians(math.Mod(m+c+180+102.9372, 360))
+	transit := julian2000 + meanNoon + 0.0053*math.Sin(mRad) - 0.0069*math.Sin(2*lambda)
+	// Declination of the sun
+	sinDecl := math.Sin(lambda) * math.Sin(radians(23.4397))
+	cosDecl := math.Cos(math.Asin(sinDecl))
+	lat := radians(r.latitude)
+	cosHourAngle := (math.Sin(radians(altitude)) - math.Sin(lat)*sinDecl) / (math.Cos(lat) * cosDecl)
+	if cosHourAngle < -1 || cosHourAngle > 1 {
+		return time.Time{}, false
+	}
+	hourAngle := math.Acos(cosHourAngle) * 180 / math.Pi
+	julian := transit + hourAngle/360
+	if morning {
+		julian = transit - hourAngle/360
+	}
+	seconds := (julian - julianUnixEpoch) * 86400
+	return time.Unix(0, int64(seconds*float64(time.Second))).UTC(), true
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
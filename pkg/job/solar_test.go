@@ -0,0 +1,45 @@
+package job
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSolarNext(t *testing.T) {
+	// London, 21 June 2021: sunrise 03:43 UTC, sunset 20:21 UTC
+	tests := []struct {
+		spec     SolarSpec
+		expected time.Time
+	}{
+		{SolarSpec{Event: "sunrise", Latitude: 51.5074, Longitude: -0.1278},
+			time.Date(2021, 6, 21, 3, 43, 0, 0, time.UTC)},
+		{SolarSpec{Event: "sunset", Latitude: 51.5074, Longitude: -0.1278},
+			time.Date(2021, 6, 21, 20, 21, 0, 0, time.UTC)},
+		{SolarSpec{Event: "sunset", Latitude: 51.5074, Longitude: -0.1278, Offset: "-30m"},
+			time.Date(2021, 6, 21, 19, 51, 0, 0, time.UTC)},
+		{SolarSpec{Event: "dusk", Latitude: 51.5074, Longitude: -0.1278},
+			time.Date(2021, 6, 21, 21, 9, 0, 0, time.UTC)},
+	}
+	currTime := time.Date(2021, 6, 21, 0, 0, 0, 0, time.UTC)
+	for _, test := range tests {
+		s, err := NewSchedule(ScheduleSpec{Solar: &test.spec})
+		if err != nil {
+			t.Errorf("Error creating solar schedule: %v", err)
+			continue
+		}
+		next, err := s.Next(currTime)
+		if err != nil {
+			t.Errorf("Error getting next %s: %v", test.spec.Event, err)
+			continue
+		}
+		if diff := next.Sub(test.expected); diff < -2*time.Minute || diff > 2*time.Minute {
+			t.Errorf("Test for solar %s failed!\nEXPECTED: %v\nNEW: %v\n", test.spec.Event, test.expected, next.UTC())
+		}
+	}
+	// No sunset during polar day in Svalbard, next one is in the end of August
+	s, _ := NewSolarSchedule(SolarSpec{Event: "sunset", Latitude: 78.22, Longitude: 15.65})
+	next, err := s.Next(currTime)
+	if err != nil || next.Before(time.Date(2021, 8, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Polar day sunset test failed, got %v, %v", next, err)
+	}
+}
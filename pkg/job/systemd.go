@@ -0,0 +1,229 @@
+package job
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// calendarShorthands are systemd.time special expressions
+var calendarShorthands = map[string]string{
+	"minutely":     "*-*-* *:*:00",
+	"hourly":       "*-*-* *:00:00",
+	"daily":        "*-*-* 00:00:00",
+	"monthly":      "*-*-01 00:00:00",
+	"weekly":       "Mon *-*-* 00:00:00",
+	"yearly":       "*-01-01 00:00:00",
+	"annually":     "*-01-01 00:00:00",
+	"quarterly":    "*-01,04,07,10-01 00:00:00",
+	"semiannually": "*-01,07-01 00:00:00",
+}
+
+// weekdayNames maps english weekday names and their 3 letter abbreviations to numbers
+var weekdayNames = map[string]int{
+	"sun": 0, "sunday": 0,
+	"mon": 1, "monday": 1,
+	"tue": 2, "tuesday": 2,
+	"wed": 3, "wednesday": 3,
+	"thu": 4, "thursday": 4,
+	"fri": 5, "friday": 5,
+	"sat": 6, "saturday": 6,
+}
+
+// ParseOnCalendar converts systemd.time calendar event expression (systemd timers OnCalendar= setting) to ScheduleSpec, e.g.
+// "Mon..Fri *-*-* 09:00:00", "*-*-01 00:00", "quarterly", "*-02~03 12:00 UTC" (the third last day of February).
+// Fractional seconds and year steps are not supported.
+func ParseOnCalendar(expr string) (ScheduleSpec, error) {
+	expr = strings.TrimSpace(expr)
+	if shorthand, ok := calendarShorthands[strings.ToLower(expr)]; ok {
+		expr = shorthand
+	}
+	fields := strings.Fields(expr)
+	if len(fields) == 0 {
+		return ScheduleSpec{}, fmt.Errorf("empty calendar expression")
+	}
+	spec := ScheduleSpec{Weekday: "*"}
+	var err error
+	// Weekdays go first and start with a letter
+	if isLetter(fields[0][0]) {
+		spec.Weekday, err = convertCalendarWeekdays(fields[0])
+		if err != nil {
+			return ScheduleSpec{}, fmt.Errorf("parse error of weekday %s: %w", fields[0], err)
+		}
+		fields = fields[1:]
+	}
+	// Timezone goes last and is neither date, nor time
+	if n := len(fields); n != 0 && !strings.ContainsAny(fields[n-1], ":") && !isCalendarDate(fields[n-1]) {
+		spec.Location = fields[n-1]
+		if _, err := time.LoadLocation(spec.Location); err != nil {
+			return ScheduleSpec{}, fmt.Errorf("parse error of timezone %s: %w", spec.Location, err)
+		}
+		fields = fields[:n-1]
+	}
+	date, clock := "*-*-*", "00:00:00"
+	for _, f := range fields {
+		switch {
+		case strings.Contains(f, ":"):
+			clock = f
+		case isCalendarDate(f):
+			date = f
+		default:
+			return ScheduleSpec{}, fmt.Errorf("unsupported calendar expression part '%s'", f)
+		}
+	}
+	if err := convertCalendarDate(date, &spec); err != nil {
+		return ScheduleSpec{}, fmt.Errorf("parse error of date %s: %w", date, err)
+	}
+	if err := convertCalendarTime(clock, &spec); err != nil {
+		return ScheduleSpec{}, fmt.Errorf("parse error of time %s: %w", clock, err)
+	}
+	return spec, nil
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isCalendarDate reports if the part looks like [Year-]Month-Day
+func isCalendarDate(s string) bool {
+	return (s[0] == '*' || (s[0] >= '0' && s[0] <= '9')) && strings.ContainsAny(s, "-~")
+}
+
+// convertCalendarDate fills Year, Month and Day from [Year-]Month-Day, "~" in place of the last "-" counts day from the month end
+func convertCalendarDate(date string, spec *ScheduleSpec) (err error) {
+	fromEnd := false
+	if i := strings.LastIndex(date, "~"); i != -1 {
+		date, fromEnd = date[:i]+"-"+date[i+1:], true
+	}
+	parts := strings.Split(date, "-")
+	switch len(parts) {
+	case 2:
+		parts = append([]string{"*"}, parts...)
+	case 3:
+	default:
+		return fmt.Errorf("date must be [Year-]Month-Day")
+	}
+	if strings.Contains(parts[0], "/") {
+		return fmt.Errorf("year steps are not supported")
+	}
+	if spec.Year, err = convertCalendarValues(parts[0]); err != nil {
+		return err
+	}
+	if spec.Month, err = convertCalendarValues(parts[1]); err != nil {
+		return err
+	}
+	if fromEnd {
+		spec.Day, err = convertCalendarLastDays(parts[2])
+		return err
+	}
+	spec.Day, err = convertCalendarValues(parts[2])
+	return err
+}
+
+// convertCalendarTime fills Hour, Minute and Second from Hour:Minute[:Second]
+func convertCalendarTime(clock string, spec *ScheduleSpec) (err error) {
+	parts := strings.Split(clock, ":")
+	switch len(parts) {
+	case 2:
+		parts = append(parts, "00")
+	case 3:
+	default:
+		return fmt.Errorf("time must be Hour:Minute[:Second]")
+	}
+	if strings.Contains(parts[2], ".") {
+		return fmt.Errorf("fractional seconds are not supported")
+	}
+	if spec.Hour, err = convertCalendarValues(parts[0]); err != nil {
+		return err
+	}
+	if spec.Minute, err = convertCalendarValues(parts[1]); err != nil {
+		return err
+	}
+	spec.Second, err = convertCalendarValues(parts[2])
+	return err
+}
+
+// convertCalendarValues converts systemd comma separated values with "a..b" ranges to ScheduleSpec syntax.
+// Ranges with steps ("a..b/c") are expanded to lists.
+func convertCalendarValues(s string) (string, error) {
+	var result []string
+	for _, entry := range strings.Split(s, ",") {
+		r := strings.Split(entry, "..")
+		switch {
+		case len(r) == 1:
+			result = append(result, entry)
+		case len(r) == 2 && strings.Contains(r[1], "/"):
+			split := strings.Split(r[1], "/")
+			first, err1 := convertString(r[0])
+			last, err2 := convertString(split[0])
+			step, err3 := convertString(split[1])
+			if err1 != nil || err2 != nil || err3 != nil || step <= 0 {
+				return "", fmt.Errorf("unsupported range '%s'", entry)
+			}
+			for i := first; i <= last; i += step {
+				result = append(result, strconv.Itoa(i))
+			}
+		case len(r) == 2:
+			result = append(result, r[0]+"-"+r[1])
+		default:
+			return "", fmt.Errorf("unsupported range '%s'", entry)
+		}
+	}
+	return strings.Join(result, ","), nil
+}
+
+// convertCalendarLastDays converts days after "~" (1 is the last day of month) to "L-n" Day entries
+func convertCalendarLastDays(s string) (string, error) {
+	var result []string
+	for _, entry := range strings.Split(s, ",") {
+		step := 0
+		if split := strings.Split(entry, "/"); len(split) == 2 {
+			n, err := convertString(split[1])
+			if err != nil || n <= 0 {
+				return "", fmt.Errorf("unsupported step '%s'", entry)
+			}
+			entry, step = split[0], n
+		}
+		n, err := convertString(entry)
+		if err != nil || n < 1 || n > 31 {
+			return "", fmt.Errorf("unsupported day from the month end '%s'", entry)
+		}
+		// "~07/1" means from the 7th last day up to the last one
+		for offset := n - 1; offset >= 0; offset -= step {
+			result = append(result, "L-"+strconv.Itoa(offset))
+			if step == 0 {
+				break
+			}
+		}
+	}
+	return strings.Join(result, ","), nil
+}
+
+// convertCalendarWeekdays converts weekday names list with ranges (e.g. "Mon..Wed,Sat") to numeric Weekday spec
+func convertCalendarWeekdays(s string) (string, error) {
+	var result []string
+	for _, entry := range strings.Split(s, ",") {
+		r := strings.Split(strings.ToLower(entry), "..")
+		first, ok := weekdayNames[r[0]]
+		if !ok {
+			return "", fmt.Errorf("unknown weekday '%s'", r[0])
+		}
+		if len(r) == 1 {
+			result = append(result, strconv.Itoa(first))
+			continue
+		}
+		last, ok := weekdayNames[r[1]]
+		if len(r) != 2 || !ok {
+			return "", fmt.Errorf("unsupported weekday range '%s'", entry)
+		}
+		// Ranges could wrap over Sunday, e.g. Sat..Mon
+		for d := first; ; d = (d + 1) % 7 {
+			result = append(result, strconv.Itoa(d))
+			if d == last {
+				break
+			}
+		}
+	}
+	return strings.Join(result, ","), nil
+}
@@ -0,0 +1,63 @@
+package job
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOnCalendarNext(t *testing.T) {
+	tests := []struct {
+		expr     string
+		currTime time.Time
+		expected time.Time
+	}{
+		{"Mon..Fri *-*-* 09:00:00 UTC",
+			time.Date(2021, 3, 6, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 3, 8, 9, 0, 0, 0, time.UTC)},
+		{"*-*-01 00:00 UTC",
+			time.Date(2021, 3, 2, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"quarterly",
+			time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC)},
+		// The third last day of February
+		{"*-02~03 12:00 UTC",
+			time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 2, 26, 12, 0, 0, 0, time.UTC)},
+		{"*-*~01 UTC",
+			time.Date(2021, 4, 5, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 4, 30, 0, 0, 0, 0, time.UTC)},
+		// The last Monday of May
+		{"Mon *-05~07/1 UTC",
+			time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 5, 31, 0, 0, 0, 0, time.UTC)},
+		{"Sat..Mon 2021..2022-*-* 10:00",
+			time.Date(2021, 3, 2, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 3, 6, 10, 0, 0, 0, time.UTC)},
+		{"*:0/15",
+			time.Date(2021, 3, 1, 0, 1, 0, 0, time.UTC),
+			time.Date(2021, 3, 1, 0, 15, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		s, err := NewSchedule(ScheduleSpec{OnCalendar: test.expr, Location: "UTC"})
+		if err != nil {
+			t.Errorf("Error creating schedule for %s: %v", test.expr, err)
+			continue
+		}
+		next, err := s.Next(test.currTime)
+		if err != nil {
+			t.Errorf("Error getting next time for %s: %v", test.expr, err)
+			continue
+		}
+		if diff := cmp.Diff(next, test.expected); diff != "" {
+			t.Errorf("Test for %s failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", test.expr, test.expected, next, diff)
+		}
+	}
+	for _, expr := range []string{"Mon..Fry", "*-*-* 00:00:00.5", "*/2-*-*", "*-*-* 00:00 Mars/Base"} {
+		if _, err := ParseOnCalendar(expr); err == nil {
+			t.Errorf("Expected parse error for %s", expr)
+		}
+	}
+}
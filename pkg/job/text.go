@@ -0,0 +1,507 @@
+package job
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// textForms lists supported text schedule forms, reported on parse errors
+const textForms = `supported forms are:
+  every second|minute|hour|day|weekday|weekend|month|year
+  every <n> seconds|minutes|hours, n divides 60 (24 for hours)
+  every monday[, wednesday and friday] ...
+  every month on the <n>th|last day
+  every year on <month> <n>
+  first|second|third|fourth|fifth|last <weekday>|day|weekday of every month
+  <n>th of every month
+  daily|weekly|monthly|yearly
+followed by optional "at <time>[ and <time>]" (9:30, 9am, 5:15pm, noon, midnight) and "in <timezone>"`
+
+// textWeekdays maps weekday names (singular and plural) to numbers
+var textWeekdays = map[string]int{
+	"sunday": 0, "sundays": 0,
+	"monday": 1, "mondays": 1,
+	"tuesday": 2, "tuesdays": 2,
+	"wednesday": 3, "wednesdays": 3,
+	"thursday": 4, "thursdays": 4,
+	"friday": 5, "fridays": 5,
+	"saturday": 6, "saturdays": 6,
+}
+
+// textMonths maps month names to numbers
+var textMonths = map[string]int{
+	"january": 1, "february": 2, "march": 3, "april": 4, "may": 5, "june": 6,
+	"july": 7, "august": 8, "september": 9, "october": 10, "november": 11, "december": 12,
+}
+
+// textOrdinals maps ordinal words to numbers, -1 is the last
+var textOrdinals = map[string]int{
+	"first": 1, "second": 2, "third": 3, "fourth": 4, "fifth": 5, "last": -1,
+}
+
+// textKeywords are the rest of known words, used for suggestions
+var textKeywords = []string{
+	"every", "each", "of", "on", "the", "at", "and", "in",
+	"seconds", "minute", "minutes", "hour", "hours", "day", "days", "weekday", "weekdays", "weekend", "month", "year",
+	"daily", "weekly", "monthly", "yearly", "noon", "midnight", "am", "pm",
+}
+
+// ParseText converts constrained english text to ScheduleSpec, e.g.
+// "every weekday at 9:30 in Europe/Kyiv", "first monday of every month at noon", "every 15 minutes".
+// Errors contain suggestions for misspelled words and the list of supported forms.
+func ParseText(text string) (ScheduleSpec, error) {
+	original := strings.Fields(strings.NewReplacer(",", " , ").Replace(text))
+	if len(original) == 0 {
+		return ScheduleSpec{}, fmt.Errorf("empty schedule text, %s", textForms)
+	}
+	words := make([]string, len(original))
+	for i, w := range original {
+		words[i] = strings.ToLower(w)
+	}
+	spec := ScheduleSpec{Second: "0", Minute: "0", Hour: "0", Day: "*", Month: "*", Weekday: "*"}
+	// Timezone goes last, it is case sensitive
+	if i := lastIndex(words, "in"); i != -1 {
+		if i != len(words)-2 {
+			return ScheduleSpec{}, fmt.Errorf("'in' must be followed by single timezone name, e.g. 'in Europe/Kyiv'")
+		}
+		spec.Location = original[i+1]
+		if _, err := time.LoadLocation(spec.Location); err != nil {
+			return ScheduleSpec{}, fmt.Errorf("unknown timezone '%s': %w", spec.Location, err)
+		}
+		words = words[:i]
+	}
+	var times []string
+	if i := lastIndex(words, "at"); i != -1 {
+		times, words = words[i+1:], words[:i]
+		if len(times) == 0 {
+			return ScheduleSpec{}, fmt.Errorf("'at' must be followed by time, e.g. 'at 9:30'")
+		}
+	}
+	timeAllowed, err := parseTextPeriod(words, &spec)
+	if err != nil {
+		return ScheduleSpec{}, err
+	}
+	if len(times) != 0 {
+		if !timeAllowed {
+			return ScheduleSpec{}, fmt.Errorf("'at' can't be used with '%s', it runs more often than daily", strings.Join(words, " "))
+		}
+		if spec.Hour, spec.Minute, spec.Second, err = parseTextTimes(times); err != nil {
+			return ScheduleSpec{}, err
+		}
+	}
+	return spec, nil
+}
+
+// parseTextPeriod fills spec from the text without "at" and "in" clauses, returns if "at" clause is allowed
+func parseTextPeriod(words []string, spec *ScheduleSpec) (bool, error) {
+	if len(words) == 0 {
+		return false, fmt.Errorf("schedule text has no period, %s", textForms)
+	}
+	switch words[0] {
+	case "daily":
+		return true, expectEnd(words[1:])
+	case "weekly":
+		spec.Weekday = "1"
+		return true, expectEnd(words[1:])
+	case "monthly":
+		spec.Day = "1"
+		return true, expectEnd(words[1:])
+	case "yearly":
+		spec.Day, spec.Month = "1", "1"
+		return true, expectEnd(words[1:])
+	case "every", "each":
+		return parseTextEvery(words[1:], spec)
+	}
+	// <ordinal> <day|weekday|monday> of every month, <n>th of every month
+	if len(words) < 4 || words[len(words)-3] != "of" || (words[len(words)-2] != "every" && words[len(words)-2] != "each") || words[len(words)-1] != "month" {
+		return false, unknownText(words, "expected 'every', 'daily' or '... of every month'")
+	}
+	if n, ok := parseTextOrdinalDay(words[0]); ok && len(words) == 4 {
+		spec.Day = strconv.Itoa(n)
+		return true, nil
+	}
+	nth, ok := textOrdinals[words[0]]
+	if !ok || len(words) != 5 {
+		return false, unknownText(words[:1], "expected ordinal, e.g. 'first', 'last' or '15th'")
+	}
+	switch words[1] {
+	case "day":
+		spec.Day = strconv.Itoa(nth)
+		if nth < 0 {
+			spec.Day = "L"
+		}
+	case "weekday":
+		if nth != 1 && nth != -1 {
+			return false, fmt.Errorf("only the first and the last weekday of month are supported")
+		}
+		spec.Day = "1W"
+		if nth < 0 {
+			spec.Day = "LW"
+		}
+	default:
+		weekday, ok := textWeekdays[words[1]]
+		if !ok {
+			return false, unknownText(words[1:2], "expected weekday, 'day' or 'weekday'")
+		}
+		spec.Weekday = fmt.Sprintf("%d#%d", weekday, nth)
+		if nth < 0 {
+			spec.Weekday = fmt.Sprintf("%dL", weekday)
+		}
+	}
+	return true, nil
+}
+
+// parseTextEvery handles words after "every"
+func parseTextEvery(words []string, spec *ScheduleSpec) (bool, error) {
+	if len(words) == 0 {
+		return false, fmt.Errorf("'every' must be followed by period, %s", textForms)
+	}
+	// every <n> seconds|minutes|hours
+	if n, err := strconv.Atoi(words[0]); err == nil {
+		if n <= 0 || len(words) != 2 {
+			return false, fmt.Errorf("expected 'every <n> seconds|minutes|hours', got 'every %s'", strings.Join(words, " "))
+		}
+		unit := strings.TrimSuffix(words[1], "s")
+		if _, ok := textStepUnits[unit]; !ok {
+			return false, unknownText(words[1:], "expected seconds, minutes or hours")
+		}
+		if err := checkTextStep(n, unit); err != nil {
+			return false, err
+		}
+		step := "*/" + strconv.Itoa(n)
+		switch unit {
+		case "second":
+			spec.Second, spec.Minute, spec.Hour = step, "*", "*"
+		case "minute":
+			spec.Minute, spec.Hour = step, "*"
+		case "hour":
+			spec.Hour = step
+		}
+		return false, nil
+	}
+	switch words[0] {
+	case "second":
+		spec.Second, spec.Minute, spec.Hour = "*", "*", "*"
+		return false, expectEnd(words[1:])
+	case "minute":
+		spec.Minute, spec.Hour = "*", "*"
+		return false, expectEnd(words[1:])
+	case "hour":
+		spec.Hour = "*"
+		return false, expectEnd(words[1:])
+	case "day":
+		return true, expectEnd(words[1:])
+	case "weekday", "weekdays":
+		spec.Weekday = "1-5"
+		return true, expectEnd(words[1:])
+	case "weekend", "weekends":
+		spec.Weekday = "0,6"
+		return true, expectEnd(words[1:])
+	case "month":
+		spec.Day = "1"
+		return true, parseTextMonthDay(words[1:], spec)
+	case "year":
+		spec.Day, spec.Month = "1", "1"
+		return true, parseTextYearDay(words[1:], spec)
+	}
+	// every monday, wednesday and friday
+	var weekdays []string
+	for _, w := range words {
+		if w == "," || w == "and" {
+			continue
+		}
+		weekday, ok := textWeekdays[w]
+		if !ok {
+			return false, unknownText([]string{w}, "expected period or weekday")
+		}
+		weekdays = append(weekdays, strconv.Itoa(weekday))
+	}
+	if len(weekdays) == 0 {
+		return false, fmt.Errorf("'every' must be followed by period, %s", textForms)
+	}
+	spec.Weekday = strings.Join(weekdays, ",")
+	return true, nil
+}
+
+// textStepUnits are sizes of 'every <n>' units within the larger unit, which the step repeats in
+var textStepUnits = map[string]struct {
+	size   int
+	larger string
+}{
+	"second": {60, "minute"},
+	"minute": {60, "hour"},
+	"hour":   {24, "day"},
+}
+
+// checkTextStep returns error with suggestions, if runs every n units don't repeat evenly within the larger unit,
+// e.g. 'every 45 minutes' fires at :00 and :45 only
+func checkTextStep(n int, unit string) error {
+	u := textStepUnits[unit]
+	if n < u.size && u.size%n == 0 {
+		return nil
+	}
+	var suggestions []string
+	if n%u.size == 0 {
+		suggestions = append(suggestions, textStep(n/u.size, u.larger))
+	} else {
+		for d := n - 1; d > 0; d-- {
+			if d < u.size && u.size%d == 0 {
+				suggestions = append(suggestions, textStep(d, unit))
+				break
+			}
+		}
+		for m := n + 1; ; m++ {
+			if m < u.size && u.size%m == 0 {
+				suggestions = append(suggestions, textStep(m, unit))
+				break
+			}
+			if m%u.size == 0 {
+				suggestions = append(suggestions, textStep(m/u.size, u.larger))
+				break
+			}
+		}
+	}
+	err := fmt.Sprintf("'every %d %ss' doesn't repeat evenly, the step must be less than %d and divide it to fit the %s",
+		n, unit, u.size, u.larger)
+	var known []string
+	for _, s := range suggestions {
+		if s != "" {
+			known = append(known, "'"+s+"'")
+		}
+	}
+	if len(known) != 0 {
+		err += ", did you mean " + strings.Join(known, " or ") + "?"
+	}
+	return errors.New(err)
+}
+
+// textStep returns the text of runs every n units, empty if the text form doesn't exist, e.g. 'every 2 days'
+func textStep(n int, unit string) string {
+	switch {
+	case n == 1:
+		return "every " + unit
+	case unit == "day":
+		return ""
+	}
+	return fmt.Sprintf("every %d %ss", n, unit)
+}
+
+// parseTextMonthDay handles "on the <n>th|last day" after "every month"
+func parseTextMonthDay(words []string, spec *ScheduleSpec) error {
+	if len(words) == 0 {
+		return nil
+	}
+	if words[0] != "on" {
+		return unknownText(words[:1], "expected 'on the <n>th'")
+	}
+	words = words[1:]
+	if len(words) != 0 && words[0] == "the" {
+		words = words[1:]
+	}
+	switch {
+	case len(words) == 2 && words[0] == "last" && words[1] == "day":
+		spec.Day = "L"
+		return nil
+	case len(words) == 1:
+		if n, ok := parseTextOrdinalDay(words[0]); ok {
+			spec.Day = strconv.Itoa(n)
+			return nil
+		}
+	}
+	return unknownText(words, "expected 'on the <n>th' or 'on the last day'")
+}
+
+// parseTextYearDay handles "on <month> <n>" or "on <n> <month>" after "every year"
+func parseTextYearDay(words []string, spec *ScheduleSpec) error {
+	if len(words) == 0 {
+		return nil
+	}
+	if words[0] != "on" || len(words) != 3 {
+		return fmt.Errorf("expected 'every year on <month> <n>', got 'every year %s'", strings.Join(words, " "))
+	}
+	monthWord, dayWord := words[1], words[2]
+	if _, ok := textMonths[monthWord]; !ok {
+		monthWord, dayWord = dayWord, monthWord
+	}
+	month, ok := textMonths[monthWord]
+	if !ok {
+		return unknownText([]string{words[1]}, "expected month name")
+	}
+	day, ok := parseTextOrdinalDay(dayWord)
+	if !ok {
+		return fmt.Errorf("expected day of month, got '%s'", dayWord)
+	}
+	spec.Month, spec.Day = strconv.Itoa(month), strconv.Itoa(day)
+	return nil
+}
+
+// parseTextOrdinalDay parses "15", "15th", "1st", "2nd", "3rd" into 1..31
+func parseTextOrdinalDay(w string) (int, bool) {
+	for _, suffix := range []string{"st", "nd", "rd", "th"} {
+		w = strings.TrimSuffix(w, suffix)
+	}
+	n, err := strconv.Atoi(w)
+	if err != nil || n < 1 || n > 31 {
+		return 0, false
+	}
+	return n, true
+}
+
+// parseTextTimes converts times list to Hour, Minute, Second specs.
+// Times must share minutes and seconds or hours, otherwise they don't fit single schedule.
+func parseTextTimes(words []string) (hour, minute, second string, err error) {
+	// "9 am" is the same as "9am"
+	var times []string
+	for _, w := range words {
+		switch {
+		case w == "," || w == "and":
+		case (w == "am" || w == "pm") && len(times) != 0:
+			times[len(times)-1] += w
+		default:
+			times = append(times, w)
+		}
+	}
+	hours, minutes, seconds := map[int]bool{}, map[int]bool{}, map[int]bool{}
+	for _, t := range times {
+		h, m, s, err := parseTextTime(t)
+		if err != nil {
+			return "", "", "", err
+		}
+		hours[h], minutes[m], seconds[s] = true, true, true
+	}
+	if len(seconds) > 1 || (len(minutes) > 1 && len(hours) > 1) {
+		return "", "", "", fmt.Errorf("times %s can't be expressed by single schedule, use several schedules", strings.Join(times, ", "))
+	}
+	return joinInts(hours), joinInts(minutes), joinInts(seconds), nil
+}
+
+// parseTextTime parses "9:30", "9:30:15", "9am", "5:15pm", "noon" and "midnight"
+func parseTextTime(t string) (hour, minute, second int, err error) {
+	switch t {
+	case "noon":
+		return 12, 0, 0, nil
+	case "midnight":
+		return 0, 0, 0, nil
+	}
+	clock, meridiem := t, ""
+	if strings.HasSuffix(t, "am") || strings.HasSuffix(t, "pm") {
+		clock, meridiem = t[:len(t)-2], t[len(t)-2:]
+	}
+	parts := strings.Split(clock, ":")
+	values := []int{0, 0, 0}
+	limits := []int{23, 59, 59}
+	if len(parts) > 3 || (meridiem == "" && len(parts) == 1) {
+		return 0, 0, 0, fmt.Errorf("unsupported time '%s', expected e.g. 9:30, 9am, 5:15pm, noon or midnight", t)
+	}
+	for i, p := range parts {
+		values[i], err = strconv.Atoi(p)
+		if err != nil || values[i] < 0 || values[i] > limits[i] {
+			return 0, 0, 0, fmt.Errorf("unsupported time '%s', expected e.g. 9:30, 9am, 5:15pm, noon or midnight", t)
+		}
+	}
+	if meridiem != "" {
+		if values[0] < 1 || values[0] > 12 {
+			return 0, 0, 0, fmt.Errorf("unsupported time '%s', hour must be 1-12 with am/pm", t)
+		}
+		values[0] %= 12
+		if meridiem == "pm" {
+			values[0] += 12
+		}
+	}
+	return values[0], values[1], values[2], nil
+}
+
+func joinInts(set map[int]bool) string {
+	ints := make([]int, 0, len(set))
+	for i := range set {
+		ints = append(ints, i)
+	}
+	sort.Ints(ints)
+	s := make([]string, len(ints))
+	for i, v := range ints {
+		s[i] = strconv.Itoa(v)
+	}
+	return strings.Join(s, ",")
+}
+
+func lastIndex(words []string, w string) int {
+	for i := len(words) - 1; i >= 0; i-- {
+		if words[i] == w {
+			return i
+		}
+	}
+	return -1
+}
+
+func expectEnd(words []string) error {
+	if len(words) != 0 {
+		return unknownText(words, "unexpected words")
+	}
+	return nil
+}
+
+// unknownText returns error for unexpected words, suggesting the closest known word for the first one
+func unknownText(words []string, expected string) error {
+	err := fmt.Sprintf("can't parse '%s': %s", strings.Join(words, " "), expected)
+	if suggestion := suggestWord(words[0]); suggestion != "" && suggestion != words[0] {
+		err += fmt.Sprintf(", did you mean '%s'?", suggestion)
+	}
+	return fmt.Errorf("%s\n%s", err, textForms)
+}
+
+// suggestWord returns the known word with the smallest edit distance, if it is close enough
+func suggestWord(w string) (suggestion string) {
+	best := len(w)/3 + 1
+	check := func(known string) {
+		// Ties are resolved alphabetically to keep suggestions stable
+		if d := editDistance(w, known); d < best || (d == best && suggestion != "" && known < suggestion) {
+			best, suggestion = d, known
+		}
+	}
+	for _, known := range textKeywords {
+		check(known)
+	}
+	for _, names := range []map[string]int{textWeekdays, textMonths, textOrdinals} {
+		for known := range names {
+			check(known)
+		}
+	}
+	return suggestion
+}
+
+// editDistance is Damerau-Levenshtein (optimal string alignment) distance between a and b,
+// swapped adjacent letters count as a single edit
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(minInt(d[i-1][j]+1, d[i][j-1]+1), d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
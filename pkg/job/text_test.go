@@ -0,0 +1,81 @@
+package job
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTextNext(t *testing.T) {
+	tests := []struct {
+		text     string
+		currTime time.Time
+		expected time.Time
+	}{
+		{"every weekday at 9:30 in Europe/Kyiv",
+			time.Date(2021, 3, 6, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 3, 8, 7, 30, 0, 0, time.UTC)},
+		{"first monday of every month at noon",
+			time.Date(2021, 3, 2, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 4, 5, 12, 0, 0, 0, time.UTC)},
+		{"last friday of every month at 5:15pm",
+			time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 3, 26, 17, 15, 0, 0, time.UTC)},
+		{"every 15 minutes",
+			time.Date(2021, 3, 1, 10, 1, 0, 0, time.UTC),
+			time.Date(2021, 3, 1, 10, 15, 0, 0, time.UTC)},
+		{"every monday, wednesday and friday at 8 am and 8pm",
+			time.Date(2021, 3, 1, 9, 0, 0, 0, time.UTC),
+			time.Date(2021, 3, 1, 20, 0, 0, 0, time.UTC)},
+		{"every month on the last day at midnight",
+			time.Date(2021, 2, 1, 0, 0, 1, 0, time.UTC),
+			time.Date(2021, 2, 28, 0, 0, 0, 0, time.UTC)},
+		{"every year on december 25th at 10:00",
+			time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 12, 25, 10, 0, 0, 0, time.UTC)},
+		{"15th of every month",
+			time.Date(2021, 3, 16, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, 4, 15, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		s, err := NewSchedule(ScheduleSpec{Text: test.text, Location: "UTC"})
+		if err != nil {
+			t.Errorf("Error creating schedule for '%s': %v", test.text, err)
+			continue
+		}
+		next, err := s.Next(test.currTime)
+		if err != nil {
+			t.Errorf("Error getting next time for '%s': %v", test.text, err)
+			continue
+		}
+		if !next.Equal(test.expected) {
+			t.Errorf("Test for '%s' failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", test.text, test.expected, next, cmp.Diff(next.UTC(), test.expected))
+		}
+	}
+	errors := []struct {
+		text, message string
+	}{
+		{"every mondey at 9:00", "did you mean 'monday'?"},
+		{"frist friday of every month", "did you mean 'first'?"},
+		{"every 5 minutes at 9:00", "more often than daily"},
+		{"every day at 9:00 and 17:30", "several schedules"},
+		{"every day at 25:00", "unsupported time"},
+		// Steps, which don't divide the larger unit, fire unevenly
+		{"every 90 minutes", "did you mean 'every 30 minutes' or 'every 2 hours'?"},
+		{"every 45 minutes", "did you mean 'every 30 minutes' or 'every hour'?"},
+		{"every 7 hours", "did you mean 'every 6 hours' or 'every 8 hours'?"},
+		{"every 100 seconds", "did you mean 'every 30 seconds' or 'every 2 minutes'?"},
+		{"every 60 seconds", "did you mean 'every minute'?"},
+		{"every 120 minutes", "did you mean 'every 2 hours'?"},
+		{"every 24 hours", "did you mean 'every day'?"},
+		{"every 48 hours", "the step must be less than 24 and divide it to fit the day"},
+	}
+	for _, test := range errors {
+		_, err := ParseText(test.text)
+		if err == nil || !strings.Contains(err.Error(), test.message) {
+			t.Errorf("Expected error with '%s' for '%s', got %v", test.message, test.text, err)
+		}
+	}
+}
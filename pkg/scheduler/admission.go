@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Tarick/tscheduler/pkg/job"
+)
+
+// waiter is the run, admitted by the job concurrency policy and waiting for its locks, resources and worker
+type waiter struct {
+	job   *job.Job
+	run   job.RunInfo
+	since time.Time
+}
+
+// acquire starts the run, if its locks are free, its resources fit the pools and there is a free worker,
+// otherwise the run waits in the queue or is skipped by the job lock or resources policy
+func (sr *Scheduler) acquire(j *job.Job, run job.RunInfo) {
+	skip, reason := sr.blocked(j)
+	if reason == nil {
+		sr.hold(j, run)
+		sr.execute(j, run, nil)
+		return
+	}
+	if skip {
+		sr.skipRun(j, run, reason)
+		return
+	}
+	sr.waiters = append(sr.waiters, waiter{job: j, run: run, since: sr.clock.Now()})
+	sr.pending[j.Id]++
+	sr.logger.Info("Job \"", j.Id, "\" run ", run.ID, " waits, ", reason)
+}
+
+// blocked returns the reason, why the job run can't start now, nil if it can, and if the run must be skipped by the job policy
+func (sr *Scheduler) blocked(j *job.Job) (skip bool, reason error) {
+	if name, held := sr.heldLock(j.Locks().Names); held {
+		holder := sr.lockHolders[name]
+		return j.Locks().Policy == job.LockSkip, fmt.Errorf("lock %s is held by job %s run %s", name, holder.jobID, holder.runID)
+	}
+	if name, exhausted := sr.exhaustedPool(j); exhausted {
+		return j.Resources().Policy == job.ResourcesSkip, fmt.Errorf("resource pool %s has %d of %d free, %d required",
+			name, sr.pools[name]-sr.poolUsage[name], sr.pools[name], j.Resources().Weights[name])
+	}
+	if sr.maxWorkers != 0 && sr.workers >= sr.maxWorkers {
+		return false, fmt.Errorf("%d of %d concurrent jobs are running", sr.workers, sr.maxWorkers)
+	}
+	return false, nil
+}
+
+// hold takes the job locks, resources and the worker for the run
+func (sr *Scheduler) hold(j *job.Job, run job.RunInfo) {
+	sr.lock(j, run)
+	sr.allocate(j)
+	sr.workers++
+	sr.queueStarted++
+}
+
+// release returns locks, resources and the worker of the finished run and starts waiting runs, which can start now
+func (sr *Scheduler) release(f finishedRun) {
+	if !f.held {
+		return
+	}
+	sr.unlock(f.job, f.run)
+	sr.free(f.job)
+	sr.workers--
+	// Waiting runs are started in the queue order, unless they are still blocked
+	sr.sortWaiters(sr.clock.Now())
+	var waiting []waiter
+	for _, w := range sr.waiters {
+		if _, reason := sr.blocked(w.job); reason != nil {
+			waiting = append(waiting, w)
+			continue
+		}
+		waited := sr.clock.Now().Sub(w.since)
+		for _, name := range w.job.Locks().Names {
+			sr.lockWaitTime[name] += waited
+		}
+		sr.queueWaitTime += waited
+		sr.pending[w.job.Id]--
+		if sr.pending[w.job.Id] <= 0 {
+			delete(sr.pending, w.job.Id)
+		}
+		sr.logger.Info("Starting job ", w.job.Id, " run ", w.run.ID, " after waiting for ", waited)
+		w.run.Started = sr.clock.Now()
+		sr.hold(w.job, w.run)
+		sr.execute(w.job, w.run, nil)
+	}
+	sr.waiters = waiting
+}
+
+// removeWaiters drops waiting runs of the job. They are finished as skipped with the error, if it is not nil.
+func (sr *Scheduler) removeWaiters(id string, err error) {
+	var waiting []waiter
+	for _, w := range sr.waiters {
+		if w.job.Id != id {
+			waiting = append(waiting, w)
+			continue
+		}
+		if err != nil {
+			sr.skipRun(w.job, w.run, err)
+		}
+	}
+	sr.waiters = waiting
+	delete(sr.pending, id)
+}
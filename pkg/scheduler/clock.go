@@ -0,0 +1,110 @@
+package scheduler
+
+import "time"
+
+// Clock provides the current time and timers to the scheduler, the system clock is used by default.
+// Tests and simulations replace it with FakeClock to control the time.
+type Clock interface {
+	Now() time.Time
+	// Elapsed returns the monotonic time since an arbitrary point, which isn't affected by the wall clock changes
+	Elapsed() time.Duration
+	NewTimer(d time.Duration) Timer
+	After(d time.Duration) <-chan time.Time
+}
+
+// Timer is the single event timer, created by Clock
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// WithClock sets the clock of scheduler
+func WithClock(c Clock) Option {
+	return func(sr *Scheduler) {
+		sr.clock = c
+	}
+}
+
+// systemClock is Clock of the time package
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// systemClockStart is the reference of the system monotonic clock
+var systemClockStart = time.Now()
+
+func (systemClock) Elapsed() time.Duration { return time.Since(systemClockStart) }
+
+func (systemClock) NewTimer(d time.Duration) Timer { return systemTimer{time.NewTimer(d)} }
+
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+type systemTimer struct {
+	*time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time { return t.Timer.C }
+
+// DefaultClockJumpThreshold is the default discrepancy of the wall time on scheduler wake up, taken as the clock jump
+const DefaultClockJumpThreshold = 10 * time.Second
+
+// WithClockJumpThreshold sets the discrepancy of the observed and expected wall time on scheduler wake up,
+// which is taken as the wall clock jump, e.g. after the system suspend or the clock adjustment
+func WithClockJumpThreshold(d time.Duration) Option {
+	return func(sr *Scheduler) {
+		sr.jumpThreshold = d
+	}
+}
+
+// checkClockJump reschedules jobs if the wall time has changed by other than the monotonic elapsed time since the reference
+// readings of the clock, taken before scheduler has gone to sleep. The wall clock jumps forward after the system suspend
+// or the clock step forward and backward after the clock step backward. The late wake up of the busy scheduler or the
+// overdue timer deadline isn't the jump, as both clocks advance alike then. On the jump forward runs, due by the wall time
+// without the jump, start as usual, the misfire policy applies to the jumped interval only.
+func (sr *Scheduler) checkClockJump(refWall time.Time, refElapsed time.Duration) {
+	now, elapsed := sr.clock.Now(), sr.clock.Elapsed()
+	// Round strips the monotonic clock reading, times are compared by the wall clock then
+	jump := now.Round(0).Sub(refWall.Round(0)) - (elapsed - refElapsed)
+	switch {
+	case jump > sr.jumpThreshold:
+		sr.logger.Warn("Wall clock jumped forward by ", jump, " (system suspend or clock adjustment), rescheduling jobs")
+		sr.tick(now.Add(-jump))
+	case jump < -sr.jumpThreshold:
+		sr.logger.Warn("Wall clock jumped backward by ", -jump, " (clock adjustment), rescheduling jobs")
+	default:
+		return
+	}
+	sr.reschedule(now)
+}
+
+// reschedule recomputes next runs of scheduled jobs from now after the wall clock jump. Runs, missed by the jump forward,
+// start according to the job misfire policy. Jobs don't fire again at times, they have already fired at before the jump backward.
+// Fixed delay jobs are not rescheduled, as their delay is the elapsed time.
+func (sr *Scheduler) reschedule(now time.Time) {
+	for _, j := range sr.orderedJobs() {
+		if j.FixedDelay() != 0 || !j.Scheduled() {
+			continue
+		}
+		after := now
+		last, err := sr.state.LastFire(j.Id)
+		if err != nil {
+			sr.logger.Error("Failure getting job \"", j.Id, "\" last fire time: ", err)
+		} else if last.After(now) {
+			after = last
+		}
+		// Job, which next run has passed, has runs within the jumped interval
+		jumped := !j.NextRun().IsZero() && !j.NextRun().After(now)
+		next, missed := j.NextRun(), len(sr.missed[j.Id])
+		if err := j.SetNextRun(after); err != nil {
+			sr.logger.Warn("Job \"", j.Id, "\" will not be scheduled further due to scheduling error: ", err)
+		} else {
+			sr.logger.Info("Job \"", j.Id, "\" next run rescheduled at: ", j.NextRun())
+		}
+		sr.schedule(j)
+		sr.checkMissed(j, now)
+		if jumped && len(sr.missed[j.Id]) == missed {
+			sr.logger.Warn("Job \"", j.Id, "\" runs since ", next, ", missed by the clock jump, are skipped by misfire policy ", j.MisfirePolicy())
+		}
+		sr.startMissed(j)
+	}
+}
@@ -0,0 +1,155 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Tarick/tscheduler/pkg/job"
+)
+
+// runCancel cancels the running run
+type runCancel struct {
+	runID  string
+	cancel context.CancelFunc
+}
+
+// errReplaced is the error of the run, killed to start the newer one by ConcurrencyReplace policy
+var errReplaced = errors.New("run was replaced by a newer one")
+
+// JobStats are the job runs, handled by scheduler
+type JobStats struct {
+	// Running is the number of running runs
+	Running int
+	// Queued is the number of runs, waiting by the job concurrency policy
+	Queued int
+	// Waiting is the number of runs, waiting for the job locks or resources
+	Waiting int
+	// Skipped is the total number of runs, skipped by the job concurrency, lock or resources policy
+	Skipped int
+}
+
+// Stats returns runs statistics of registered jobs by job ID
+func (sr *Scheduler) Stats() (stats map[string]JobStats) {
+	sr.query(func() { stats = sr.stats() })
+	return
+}
+
+func (sr *Scheduler) stats() map[string]JobStats {
+	stats := make(map[string]JobStats, len(sr.jobs))
+	for id := range sr.jobs {
+		stats[id] = JobStats{
+			Running: sr.runs[id],
+			Queued:  len(sr.queued[id]),
+			Waiting: sr.pending[id],
+			Skipped: sr.skipped[id],
+		}
+	}
+	return stats
+}
+
+// active returns the number of running runs of the job and the ones, waiting for locks
+func (sr *Scheduler) active(id string) int {
+	return sr.runs[id] + sr.pending[id]
+}
+
+// startRun starts job run, if the job concurrency policy admits it
+func (sr *Scheduler) startRun(j *job.Job, run job.RunInfo) {
+	p := j.ConcurrencyPolicy()
+	if p.Max == 0 || sr.active(j.Id) < p.Max {
+		sr.acquire(j, run)
+		return
+	}
+	switch p.Policy {
+	case job.ConcurrencyQueue:
+		if len(sr.queued[j.Id]) >= p.MaxQueuedRuns() {
+			sr.skipRun(j, run, fmt.Errorf("job has reached %d running and %d queued runs", p.Max, p.MaxQueuedRuns()))
+			return
+		}
+		sr.queued[j.Id] = append(sr.queued[j.Id], run)
+		sr.logger.Info("Job \"", j.Id, "\" has reached ", p.Max, " running runs, run ", run.ID, " is queued")
+	case job.ConcurrencyReplace:
+		// Replaced runs still run until they exit, the ones, which aren't replaced yet, are counted
+		sr.replaceRuns(j, run, len(sr.cancels[j.Id])+sr.pending[j.Id]-p.Max+1)
+		sr.acquire(j, run)
+	default:
+		sr.skipRun(j, run, fmt.Errorf("job has reached %d running runs", p.Max))
+	}
+}
+
+// replaceRuns replaces n oldest runs of the job with the run: running runs, as the older ones, are killed first,
+// then the waiting runs in the queue order are finished as skipped
+func (sr *Scheduler) replaceRuns(j *job.Job, run job.RunInfo, n int) {
+	for ; n > 0 && len(sr.cancels[j.Id]) != 0; n-- {
+		oldest := sr.cancels[j.Id][0]
+		sr.logger.Warn("Job \"", j.Id, "\" run ", oldest.runID, " is killed to start run ", run.ID)
+		oldest.cancel()
+		sr.removeCancel(j.Id, oldest.runID)
+	}
+	if n <= 0 {
+		return
+	}
+	var waiting []waiter
+	for _, w := range sr.waiters {
+		if w.job.Id != j.Id || n == 0 {
+			waiting = append(waiting, w)
+			continue
+		}
+		n--
+		sr.pending[j.Id]--
+		sr.skipRun(w.job, w.run, fmt.Errorf("run was replaced by run %s while waiting", run.ID))
+	}
+	sr.waiters = waiting
+	if sr.pending[j.Id] <= 0 {
+		delete(sr.pending, j.Id)
+	}
+}
+
+// removeCancel forgets cancel of the finished or replaced run
+func (sr *Scheduler) removeCancel(jobID, runID string) {
+	cancels := sr.cancels[jobID]
+	for i, c := range cancels {
+		if c.runID == runID {
+			cancels = append(cancels[:i:i], cancels[i+1:]...)
+			break
+		}
+	}
+	if len(cancels) == 0 {
+		delete(sr.cancels, jobID)
+		return
+	}
+	sr.cancels[jobID] = cancels
+}
+
+// WithSkipHandler sets the function, called by scheduler goroutine for each run, skipped by the job concurrency, lock or
+// resources policy, e.g. to count skipped runs in metrics. The job command isn't called for such runs.
+func WithSkipHandler(f func(jobID string, run job.RunInfo, err error)) Option {
+	return func(sr *Scheduler) {
+		sr.onSkip = f
+	}
+}
+
+// skipRun finishes the run as skipped by concurrency policy without running the job
+func (sr *Scheduler) skipRun(j *job.Job, run job.RunInfo, err error) {
+	sr.skipped[j.Id]++
+	if sr.onSkip != nil {
+		sr.onSkip(j.Id, run, err)
+	}
+	sr.execute(j, run, fmt.Errorf("%v: %w", err, job.ErrSkipped))
+}
+
+// startQueued starts queued runs of the job, as long as the job concurrency policy admits them
+func (sr *Scheduler) startQueued(j *job.Job) {
+	p := j.ConcurrencyPolicy()
+	for len(sr.queued[j.Id]) != 0 && (p.Max == 0 || sr.active(j.Id) < p.Max) {
+		run := sr.queued[j.Id][0]
+		if len(sr.queued[j.Id]) == 1 {
+			delete(sr.queued, j.Id)
+		} else {
+			sr.queued[j.Id] = sr.queued[j.Id][1:]
+		}
+		sr.logger.Info("Starting queued run ", run.ID, " of job ", j.Id, ", waited for ", sr.clock.Now().Sub(run.Started))
+		run.Started = sr.clock.Now()
+		sr.acquire(j, run)
+	}
+}
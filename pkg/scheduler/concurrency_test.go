@@ -0,0 +1,120 @@
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tarick/tscheduler/pkg/job"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+)
+
+func TestConcurrency(t *testing.T) {
+	testData := []struct {
+		policy   job.ConcurrencyPolicy
+		expected []string
+	}{
+		{job.ConcurrencyPolicy{Max: 1}, []string{RunSuccess, RunSkipped, RunSkipped}},
+		{job.ConcurrencyPolicy{Max: 2}, []string{RunSuccess, RunSuccess, RunSkipped}},
+		{job.ConcurrencyPolicy{Max: 0}, []string{RunSuccess, RunSuccess, RunSuccess}},
+		{job.ConcurrencyPolicy{Max: 1, Policy: job.ConcurrencyQueue, MaxQueued: 1}, []string{RunSuccess, RunSuccess, RunSkipped}},
+		{job.ConcurrencyPolicy{Max: 1, Policy: job.ConcurrencyReplace}, []string{RunFailed, RunFailed, RunSuccess}},
+		// The oldest run only is replaced to keep the limit
+		{job.ConcurrencyPolicy{Max: 2, Policy: job.ConcurrencyReplace}, []string{RunFailed, RunSuccess, RunSuccess}},
+	}
+	for _, d := range testData {
+		var skipped []string
+		sr := New(zap.NewNop().Sugar(), WithSkipHandler(func(jobID string, run job.RunInfo, err error) {
+			skipped = append(skipped, run.ID)
+		}))
+		release := make(chan struct{})
+		command := func(ctx context.Context, run job.RunInfo) error {
+			select {
+			case <-release:
+			case <-ctx.Done():
+			}
+			return ctx.Err()
+		}
+		j, err := job.New("test", command, nil, job.WithConcurrencyPolicy(d.policy))
+		if err != nil {
+			t.Fatalf("Error creating job: %v", err)
+		}
+		sr.AddJob(j)
+		var runs []string
+		for i := 0; i < 3; i++ {
+			run := job.RunInfo{ID: string(rune('a' + i)), Started: time.Now(), Attempt: 1}
+			runs = append(runs, run.ID)
+			sr.startRun(j, run)
+		}
+		close(release)
+		got := make([]string, len(runs))
+		for processed := 0; processed < len(runs); {
+			select {
+			case <-sr.wake:
+				sr.finishedMutex.Lock()
+				processed += len(sr.finished)
+				sr.finishedMutex.Unlock()
+				sr.processFinished()
+			case <-time.After(5 * time.Second):
+				t.Fatalf("Runs haven't finished with policy %v", d.policy)
+			}
+		}
+		history, err := sr.History("test")
+		if err != nil {
+			t.Fatalf("Error getting history: %v", err)
+		}
+		for _, r := range history {
+			for i, id := range runs {
+				if r.RunID == id {
+					got[i] = r.Status
+				}
+			}
+		}
+		if diff := cmp.Diff(got, d.expected); diff != "" {
+			t.Errorf("Test for policy %v failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", d.policy, d.expected, got, diff)
+		}
+		var expectedSkipped []string
+		for i, status := range d.expected {
+			if status == RunSkipped {
+				expectedSkipped = append(expectedSkipped, runs[i])
+			}
+		}
+		if diff := cmp.Diff(skipped, expectedSkipped); diff != "" {
+			t.Errorf("Skip handler for policy %v failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", d.policy, expectedSkipped, skipped, diff)
+		}
+		for _, r := range history {
+			if r.Status == RunFailed && !strings.HasPrefix(r.Error, errReplaced.Error()) {
+				t.Errorf("Run %v must be replaced, got error %v", r.RunID, r.Error)
+			}
+		}
+	}
+}
+
+func TestStatsStop(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	sr := New(zap.NewNop().Sugar(), WithClock(clock))
+	sr.Start()
+	clock.BlockUntil(1)
+	// Requests, racing scheduler stop, return instead of blocking
+	returned := make(chan struct{})
+	go func() {
+		defer close(returned)
+		for {
+			select {
+			case <-sr.doneChan():
+				sr.Stats()
+				return
+			default:
+				sr.Stats()
+			}
+		}
+	}()
+	sr.Stop()
+	select {
+	case <-returned:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Stats request is blocked by scheduler stop")
+	}
+}
@@ -0,0 +1,125 @@
+package scheduler
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// FakeClock is Clock, which time changes only when advanced manually.
+// Timers measure the elapsed time, like the system monotonic clock, and fire in order of deadlines.
+type FakeClock struct {
+	mutex   sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	elapsed time.Duration
+	timers  []*fakeTimer
+}
+
+// NewFakeClock creates FakeClock, set to the time
+func NewFakeClock(t time.Time) *FakeClock {
+	c := &FakeClock{now: t}
+	c.changed = sync.NewCond(&c.mutex)
+	return c
+}
+
+// Now returns the clock time
+func (c *FakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+// Elapsed returns the time, the clock has been advanced by, Set doesn't change it
+func (c *FakeClock) Elapsed() time.Duration {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.elapsed
+}
+
+// NewTimer creates timer, which fires when the clock is advanced by d, timer with non-positive d fires right away
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	t := &fakeTimer{clock: c, c: make(chan time.Time, 1), at: c.elapsed + d}
+	if d <= 0 {
+		t.c <- c.now
+		return t
+	}
+	c.timers = append(c.timers, t)
+	c.changed.Broadcast()
+	return t
+}
+
+// After returns the channel of the timer, created by NewTimer
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// Advance moves the clock forward by d, firing due timers
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+	c.elapsed += d
+	sort.SliceStable(c.timers, func(i, k int) bool { return c.timers[i].at < c.timers[k].at })
+	var pending []*fakeTimer
+	for _, timer := range c.timers {
+		if timer.at > c.elapsed {
+			pending = append(pending, timer)
+			continue
+		}
+		timer.c <- c.now
+	}
+	c.timers = pending
+	c.changed.Broadcast()
+}
+
+// Set sets the clock time without firing timers, like the wall clock adjustment or the system suspend.
+// Time may go backwards.
+func (c *FakeClock) Set(t time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = t
+}
+
+// Timers returns the number of timers, which haven't fired or stopped yet
+func (c *FakeClock) Timers() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil waits until there are n timers, which haven't fired or stopped yet.
+// Scheduler waits for its single timer, when it is done with the current wake up.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for len(c.timers) != n {
+		c.changed.Wait()
+	}
+}
+
+type fakeTimer struct {
+	clock *FakeClock
+	c     chan time.Time
+	// at is the clock elapsed time, when the timer fires
+	at time.Duration
+}
+
+func (t *fakeTimer) C() <-chan time.Time { return t.c }
+
+// Stop stops the timer, reports false if the timer has already fired or stopped
+func (t *fakeTimer) Stop() bool {
+	c := t.clock
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for i, timer := range c.timers {
+		if timer == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.changed.Broadcast()
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,226 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Tarick/tscheduler/pkg/job"
+)
+
+// Run statuses, the same as in metrics
+const (
+	RunSuccess = "success"
+	RunFailed  = "failed"
+	RunSkipped = "skipped"
+)
+
+// DefaultHistorySize is the number of runs kept per job by default
+const DefaultHistorySize = 20
+
+// RunRecord is the result of the finished job run
+type RunRecord struct {
+	JobID     string        `json:"job_id"`
+	RunID     string        `json:"run_id"`
+	Attempt   int           `json:"attempt"`
+	Scheduled time.Time     `json:"scheduled"`
+	Started   time.Time     `json:"started"`
+	Finished  time.Time     `json:"finished"`
+	Duration  time.Duration `json:"duration"`
+	Status    string        `json:"status"`
+	// ExitCode is the exit code of the job command, -1 if it wasn't run or didn't exit normally
+	ExitCode int    `json:"exit_code"`
+	Error    string `json:"error,omitempty"`
+	// Manual is true for the run, started on demand
+	Manual bool `json:"manual,omitempty"`
+}
+
+func (r RunRecord) String() string {
+	s := fmt.Sprintf("%v #%d %s: scheduled %v, started %v, took %v, exit code %d",
+		r.RunID, r.Attempt, r.Status, r.Scheduled.Format(time.RFC3339), r.Started.Format(time.RFC3339), r.Duration, r.ExitCode)
+	if r.Manual {
+		s += ", started manually"
+	}
+	if r.Error != "" {
+		s += ", error: " + r.Error
+	}
+	return s
+}
+
+// newRunRecord creates record of the run, which has returned err
+func newRunRecord(jobID string, run job.RunInfo, err error, finished time.Time) RunRecord {
+	r := RunRecord{
+		JobID:     jobID,
+		RunID:     run.ID,
+		Attempt:   run.Attempt,
+		Scheduled: run.Scheduled,
+		Started:   run.Started,
+		Finished:  finished,
+		Duration:  finished.Sub(run.Started),
+		Status:    RunSuccess,
+		ExitCode:  -1,
+		Manual:    run.Manual,
+	}
+	var exitErr interface{ ExitCode() int }
+	switch {
+	case err == nil:
+		r.ExitCode = 0
+	case errors.Is(err, job.ErrSkipped):
+		r.Status = RunSkipped
+	case errors.As(err, &exitErr):
+		r.ExitCode = exitErr.ExitCode()
+		fallthrough
+	default:
+		r.Status = RunFailed
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	return r
+}
+
+// HistoryStore keeps finished runs of jobs. Implementations must be safe for concurrent use.
+type HistoryStore interface {
+	// Add records the finished run
+	Add(r RunRecord) error
+	// History returns the kept runs of the job, the newest first
+	History(jobID string) ([]RunRecord, error)
+	// Summary returns the job runs summary, which is kept regardless of runs retention
+	Summary(jobID string) (RunSummary, error)
+	// Close releases resources of the store
+	Close() error
+}
+
+// HistoryRetention limits the runs, kept in HistoryStore
+type HistoryRetention struct {
+	// MaxRuns is the maximum number of runs per job, 0 means DefaultHistorySize
+	MaxRuns int
+	// MaxAge drops runs, finished earlier than MaxAge ago, 0 means no age limit
+	MaxAge time.Duration
+}
+
+// RunSummary aggregates all runs of the job
+type RunSummary struct {
+	LastSuccess time.Time `json:"last_success"`
+	LastFailure time.Time `json:"last_failure"`
+	// FailureStreak is the number of consecutive failed runs since the last success
+	FailureStreak int `json:"failure_streak"`
+}
+
+func (s RunSummary) String() string {
+	return fmt.Sprintf("last success: %v, last failure: %v, failures in a row: %d",
+		formatTime(s.LastSuccess), formatTime(s.LastFailure), s.FailureStreak)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Format(time.RFC3339)
+}
+
+// update accounts the run in summary, skipped runs are ignored
+func (s *RunSummary) update(r RunRecord) {
+	switch r.Status {
+	case RunSuccess:
+		s.LastSuccess = r.Finished
+		s.FailureStreak = 0
+	case RunFailed:
+		s.LastFailure = r.Finished
+		s.FailureStreak++
+	}
+}
+
+// memoryHistoryStore keeps runs in memory only
+type memoryHistoryStore struct {
+	retention HistoryRetention
+	mutex     sync.Mutex
+	runs      map[string][]RunRecord
+	summaries map[string]RunSummary
+	// clock is the clock of scheduler, runs older than MaxAge by it are dropped
+	clock Clock
+}
+
+// clockSetter is the store, which takes the clock of scheduler to apply the retention
+type clockSetter interface {
+	setClock(c Clock)
+}
+
+// NewMemoryHistoryStore creates HistoryStore, which loses the runs on restart
+func NewMemoryHistoryStore(retention HistoryRetention) HistoryStore {
+	return newMemoryHistoryStore(retention)
+}
+
+func newMemoryHistoryStore(retention HistoryRetention) *memoryHistoryStore {
+	if retention.MaxRuns <= 0 {
+		retention.MaxRuns = DefaultHistorySize
+	}
+	return &memoryHistoryStore{
+		retention: retention,
+		runs:      make(map[string][]RunRecord),
+		summaries: make(map[string]RunSummary),
+		clock:     systemClock{},
+	}
+}
+
+func (h *memoryHistoryStore) setClock(c Clock) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.clock = c
+}
+
+// Add appends the run, dropping the oldest ones beyond retention
+func (h *memoryHistoryStore) Add(r RunRecord) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.add(r, h.clock.Now())
+	return nil
+}
+
+func (h *memoryHistoryStore) add(r RunRecord, now time.Time) {
+	summary := h.summaries[r.JobID]
+	summary.update(r)
+	h.summaries[r.JobID] = summary
+	runs := append(h.runs[r.JobID], r)
+	if len(runs) > h.retention.MaxRuns {
+		runs = runs[len(runs)-h.retention.MaxRuns:]
+	}
+	h.runs[r.JobID] = h.prune(runs, now)
+}
+
+// prune drops runs older than MaxAge, runs are sorted from the oldest one
+func (h *memoryHistoryStore) prune(runs []RunRecord, now time.Time) []RunRecord {
+	if h.retention.MaxAge == 0 {
+		return runs
+	}
+	i := 0
+	for i < len(runs) && now.Sub(runs[i].Finished) > h.retention.MaxAge {
+		i++
+	}
+	return runs[i:]
+}
+
+// History returns copy of the job runs, the newest first
+func (h *memoryHistoryStore) History(jobID string) ([]RunRecord, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	runs := h.prune(h.runs[jobID], h.clock.Now())
+	result := make([]RunRecord, len(runs))
+	for i, r := range runs {
+		result[len(runs)-1-i] = r
+	}
+	return result, nil
+}
+
+// Summary returns the job runs summary
+func (h *memoryHistoryStore) Summary(jobID string) (RunSummary, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.summaries[jobID], nil
+}
+
+// Close does nothing for memory store
+func (h *memoryHistoryStore) Close() error {
+	return nil
+}
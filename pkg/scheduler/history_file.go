@@ -0,0 +1,197 @@
+package scheduler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+)
+
+// compactMinLines is the minimum number of log lines before compaction is considered
+const compactMinLines = 1000
+
+// historyLogEntry is a line of history log, either the run or the job summary, written on compaction
+type historyLogEntry struct {
+	Run     *RunRecord  `json:"run,omitempty"`
+	JobID   string      `json:"job_id,omitempty"`
+	Summary *RunSummary `json:"summary,omitempty"`
+}
+
+// FileHistoryStore keeps runs in append-only JSON lines log file and in memory for reading.
+// The log is compacted on open and when it has grown twice over the retained runs.
+type FileHistoryStore struct {
+	path   string
+	mutex  sync.Mutex
+	file   *os.File
+	lines  int
+	memory *memoryHistoryStore
+}
+
+// NewFileHistoryStore opens or creates history log at path
+func NewFileHistoryStore(path string, retention HistoryRetention) (*FileHistoryStore, error) {
+	s := &FileHistoryStore{path: path, memory: newMemoryHistoryStore(retention)}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// load replays the log into memory store
+func (s *FileHistoryStore) load() error {
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failure reading history log %s: %w", s.path, err)
+	}
+	now := s.memory.clock.Now()
+	reader := bufio.NewReader(bytes.NewReader(data))
+	for n := 1; ; n++ {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// Unterminated last line is the interrupted write
+			return nil
+		}
+		var entry historyLogEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return fmt.Errorf("failure parsing history log %s line %d: %w", s.path, n, err)
+		}
+		switch {
+		case entry.Run != nil:
+			s.memory.add(*entry.Run, now)
+		case entry.Summary != nil:
+			s.memory.summaries[entry.JobID] = *entry.Summary
+		}
+	}
+}
+
+// compact rewrites the log with retained runs and summaries only, then reopens it for appending
+func (s *FileHistoryStore) compact() error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	now := s.memory.clock.Now()
+	lines := 0
+	var runs []RunRecord
+	for jobID, jobRuns := range s.memory.runs {
+		jobRuns = s.memory.prune(jobRuns, now)
+		s.memory.runs[jobID] = jobRuns
+		runs = append(runs, jobRuns...)
+	}
+	sort.SliceStable(runs, func(i, k int) bool { return runs[i].Finished.Before(runs[k].Finished) })
+	for i := range runs {
+		if err := encoder.Encode(historyLogEntry{Run: &runs[i]}); err != nil {
+			return err
+		}
+		lines++
+	}
+	// Summaries go last to replace the ones, counted from the retained runs on load
+	for jobID, summary := range s.memory.summaries {
+		summary := summary
+		if err := encoder.Encode(historyLogEntry{JobID: jobID, Summary: &summary}); err != nil {
+			return err
+		}
+		lines++
+	}
+	tmp := s.path + ".tmp"
+	if err := writeFileSync(tmp, buf.Bytes()); err != nil {
+		return fmt.Errorf("failure writing history log %s: %w", tmp, err)
+	}
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failure replacing history log %s: %w", s.path, err)
+	}
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failure opening history log %s: %w", s.path, err)
+	}
+	s.file = file
+	s.lines = lines
+	return nil
+}
+
+func writeFileSync(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (s *FileHistoryStore) setClock(c Clock) {
+	s.memory.setClock(c)
+}
+
+// Add appends the run to the log, compacting it when needed
+func (s *FileHistoryStore) Add(r RunRecord) error {
+	line, err := json.Marshal(historyLogEntry{Run: &r})
+	if err != nil {
+		return err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.file == nil {
+		return fmt.Errorf("history log %s is closed", s.path)
+	}
+	s.memory.mutex.Lock()
+	defer s.memory.mutex.Unlock()
+	s.memory.add(r, s.memory.clock.Now())
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failure writing history log %s: %w", s.path, err)
+	}
+	s.lines++
+	if retained := s.retained(); s.lines > compactMinLines && s.lines > 2*retained {
+		return s.compact()
+	}
+	return nil
+}
+
+// retained returns the number of runs and summaries in memory
+func (s *FileHistoryStore) retained() int {
+	n := len(s.memory.summaries)
+	for _, runs := range s.memory.runs {
+		n += len(runs)
+	}
+	return n
+}
+
+// History returns the kept runs of the job, the newest first
+func (s *FileHistoryStore) History(jobID string) ([]RunRecord, error) {
+	return s.memory.History(jobID)
+}
+
+// Summary returns the job runs summary
+func (s *FileHistoryStore) Summary(jobID string) (RunSummary, error) {
+	return s.memory.Summary(jobID)
+}
+
+// Close closes the log file
+func (s *FileHistoryStore) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
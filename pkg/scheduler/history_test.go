@@ -0,0 +1,141 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Tarick/tscheduler/pkg/job"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+)
+
+func TestNewRunRecord(t *testing.T) {
+	started := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	run := job.RunInfo{ID: "1", Scheduled: started, Started: started, Attempt: 1}
+	exitErr := exec.Command("sh", "-c", "exit 3").Run()
+	testData := []struct {
+		err      error
+		status   string
+		exitCode int
+	}{
+		{nil, RunSuccess, 0},
+		{fmt.Errorf("already running: %w", job.ErrSkipped), RunSkipped, -1},
+		{fmt.Errorf("command failed: %w", exitErr), RunFailed, 3},
+		{errors.New("start failed"), RunFailed, -1},
+	}
+	for _, d := range testData {
+		r := newRunRecord("test", run, d.err, started.Add(time.Second))
+		if r.Status != d.status || r.ExitCode != d.exitCode || r.Duration != time.Second {
+			t.Errorf("Test for error %v failed!\nEXPECTED: \n %v %v\nNEW: \n %v\n", d.err, d.status, d.exitCode, r)
+		}
+	}
+}
+
+func TestMemoryHistoryStore(t *testing.T) {
+	h := NewMemoryHistoryStore(HistoryRetention{MaxRuns: 2, MaxAge: time.Hour})
+	now := time.Now()
+	h.Add(RunRecord{JobID: "test", RunID: "0", Status: RunSuccess, Finished: now.Add(-2 * time.Hour)})
+	for _, id := range []string{"1", "2", "3"} {
+		h.Add(RunRecord{JobID: "test", RunID: id, Status: RunFailed, Finished: now})
+	}
+	h.Add(RunRecord{JobID: "test", RunID: "4", Status: RunSkipped, Finished: now})
+	h.Add(RunRecord{JobID: "old", RunID: "5", Finished: now.Add(-2 * time.Hour)})
+	runs, _ := h.History("test")
+	var got []string
+	for _, r := range runs {
+		got = append(got, r.RunID)
+	}
+	expected := []string{"4", "3"}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("Test for history failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", expected, got, diff)
+	}
+	if runs, _ := h.History("old"); len(runs) != 0 {
+		t.Errorf("Runs older than max age must be dropped, got %v", runs)
+	}
+	summary, _ := h.Summary("test")
+	if summary.FailureStreak != 3 || !summary.LastSuccess.Equal(now.Add(-2*time.Hour)) {
+		t.Errorf("Test for summary failed, got %v", summary)
+	}
+}
+
+func TestHistoryMaxAge(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	runs := make(testRuns, 10)
+	sr := New(zap.NewNop().Sugar(), WithClock(clock),
+		WithHistoryStore(NewMemoryHistoryStore(HistoryRetention{MaxAge: 90 * time.Minute})))
+	if err := sr.AddJob(newTestJob(t, runs, "hourly", "every hour")); err != nil {
+		t.Fatalf("Error adding job: %v", err)
+	}
+	sr.Start()
+	t.Cleanup(func() { sr.Stop() })
+	var got []string
+	for i := 0; i < 3; i++ {
+		advance(clock, time.Hour)
+		runs.receive(t, 1)
+		waitIdle(t, sr)
+	}
+	history, err := sr.History("hourly")
+	if err != nil {
+		t.Fatalf("Error getting history: %v", err)
+	}
+	for _, r := range history {
+		got = append(got, r.Scheduled.Format("15:04"))
+	}
+	// The run at 11:00 is 2 hours old by the scheduler clock
+	expected := []string{"13:00", "12:00"}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("Test for history max age failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", expected, got, diff)
+	}
+}
+
+func TestFileHistoryStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.log")
+	h, err := NewFileHistoryStore(path, HistoryRetention{MaxRuns: 3})
+	if err != nil {
+		t.Fatalf("Error creating history store: %v", err)
+	}
+	now := time.Now().Round(0)
+	if err := h.Add(RunRecord{JobID: "test", RunID: "success", Status: RunSuccess, Finished: now}); err != nil {
+		t.Fatalf("Error adding run: %v", err)
+	}
+	// Enough to compact the log
+	for i := 0; i < compactMinLines; i++ {
+		if err := h.Add(RunRecord{JobID: "test", RunID: strconv.Itoa(i), Status: RunFailed, Finished: now}); err != nil {
+			t.Fatalf("Error adding run: %v", err)
+		}
+	}
+	h.Close()
+	// Interrupted write
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	f.WriteString(`{"run":{"job_id":"te`)
+	f.Close()
+
+	h, err = NewFileHistoryStore(path, HistoryRetention{MaxRuns: 3})
+	if err != nil {
+		t.Fatalf("Error opening history store: %v", err)
+	}
+	defer h.Close()
+	runs, _ := h.History("test")
+	var got []string
+	for _, r := range runs {
+		got = append(got, r.RunID)
+	}
+	expected := []string{"999", "998", "997"}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("Test for file history failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", expected, got, diff)
+	}
+	summary, _ := h.Summary("test")
+	expectedSummary := RunSummary{LastSuccess: now, LastFailure: now, FailureStreak: compactMinLines}
+	if diff := cmp.Diff(summary, expectedSummary); diff != "" {
+		t.Errorf("Test for file history summary failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", expectedSummary, summary, diff)
+	}
+	if h.lines != 4 {
+		t.Errorf("Log must be compacted on open to 3 runs and summary, got %d lines", h.lines)
+	}
+}
@@ -0,0 +1,73 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/Tarick/tscheduler/pkg/job"
+)
+
+// lockHolder is the run, holding the lock
+type lockHolder struct {
+	jobID string
+	runID string
+}
+
+// LockStats is the state of the named lock
+type LockStats struct {
+	// Holder is the ID of the job, holding the lock, empty if the lock is free
+	Holder string
+	// Waiting is the number of runs, waiting for the lock
+	Waiting int
+	// WaitTime is the total time, runs have waited for the lock
+	WaitTime time.Duration
+}
+
+// Locks returns the state of locks of registered jobs by lock name
+func (sr *Scheduler) Locks() (locks map[string]LockStats) {
+	sr.query(func() { locks = sr.lockStats() })
+	return
+}
+
+func (sr *Scheduler) lockStats() map[string]LockStats {
+	locks := make(map[string]LockStats)
+	for _, e := range sr.jobs {
+		for _, name := range e.job.Locks().Names {
+			locks[name] = LockStats{Holder: sr.lockHolders[name].jobID, WaitTime: sr.lockWaitTime[name]}
+		}
+	}
+	for _, w := range sr.waiters {
+		for _, name := range w.job.Locks().Names {
+			if l, ok := locks[name]; ok {
+				l.Waiting++
+				locks[name] = l
+			}
+		}
+	}
+	return locks
+}
+
+// heldLock returns the first of named locks, which is held by some run
+func (sr *Scheduler) heldLock(names []string) (string, bool) {
+	for _, name := range names {
+		if _, ok := sr.lockHolders[name]; ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// lock marks the job locks as held by the run
+func (sr *Scheduler) lock(j *job.Job, run job.RunInfo) {
+	for _, name := range j.Locks().Names {
+		sr.lockHolders[name] = lockHolder{jobID: j.Id, runID: run.ID}
+	}
+}
+
+// unlock releases the job locks, held by the run
+func (sr *Scheduler) unlock(j *job.Job, run job.RunInfo) {
+	for _, name := range j.Locks().Names {
+		if holder, ok := sr.lockHolders[name]; ok && holder.runID == run.ID && holder.jobID == j.Id {
+			delete(sr.lockHolders, name)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Tarick/tscheduler/pkg/job"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+)
+
+func TestLocks(t *testing.T) {
+	sr := New(zap.NewNop().Sugar())
+	started := make(chan string, 10)
+	release := make(chan struct{})
+	command := func(id string) func(context.Context, job.RunInfo) error {
+		return func(ctx context.Context, run job.RunInfo) error {
+			started <- id
+			<-release
+			return nil
+		}
+	}
+	jobs := []struct {
+		id    string
+		locks job.Locks
+	}{
+		{"backup", job.Locks{Names: []string{"db"}}},
+		{"vacuum", job.Locks{Names: []string{"db", "disk"}}},
+		{"report", job.Locks{Names: []string{"db"}, Policy: job.LockSkip}},
+		{"cleanup", job.Locks{Names: []string{"disk"}}},
+	}
+	for _, d := range jobs {
+		j, err := job.New(d.id, command(d.id), nil, job.WithLocks(d.locks))
+		if err != nil {
+			t.Fatalf("Error creating job %v: %v", d.id, err)
+		}
+		sr.AddJob(j)
+		sr.startJob(j, time.Now())
+	}
+	// Cleanup doesn't wait for vacuum, which hasn't got its locks
+	var got []string
+	for i := 0; i < 2; i++ {
+		got = append(got, <-started)
+	}
+	sort.Strings(got)
+	locks := sr.Locks()
+	if locks["db"].Holder != "backup" || locks["db"].Waiting != 1 || locks["disk"].Holder != "cleanup" {
+		t.Errorf("Unexpected locks state: %v", locks)
+	}
+	close(release)
+	for finished := 0; finished < 4; {
+		select {
+		case <-sr.wake:
+			sr.finishedMutex.Lock()
+			finished += len(sr.finished)
+			sr.finishedMutex.Unlock()
+			sr.processFinished()
+		case id := <-started:
+			got = append(got, id)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Runs haven't finished, started %v", got)
+		}
+	}
+	// Runs send their start before they finish
+	for len(started) != 0 {
+		got = append(got, <-started)
+	}
+	expected := []string{"backup", "cleanup", "vacuum"}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("Test for locks failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", expected, got, diff)
+	}
+	history, err := sr.History("report")
+	if err != nil || len(history) != 1 || history[0].Status != RunSkipped {
+		t.Errorf("Report run must be skipped, got %v %v", history, err)
+	}
+	if stats := sr.Stats()["report"]; stats.Skipped != 1 {
+		t.Errorf("Report run must be counted as skipped, got %v", stats)
+	}
+	if locks := sr.Locks(); locks["db"].WaitTime == 0 || locks["db"].Holder != "" {
+		t.Errorf("Lock wait time must be recorded and lock released, got %v", locks)
+	}
+}
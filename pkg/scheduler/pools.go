@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"fmt"
+
+	"github.com/Tarick/tscheduler/pkg/job"
+)
+
+// PoolStats is the utilization of the resource pool
+type PoolStats struct {
+	Size int
+	// Used is the sum of weights of running runs
+	Used int
+	// Waiting is the number of runs, waiting for the pool capacity
+	Waiting int
+}
+
+func (p PoolStats) String() string {
+	return fmt.Sprintf("%d/%d used, %d waiting", p.Used, p.Size, p.Waiting)
+}
+
+// WithPools sets sizes of resource pools by pool name. Jobs run only when their resource weights fit the free capacity of the pools.
+func WithPools(pools map[string]int) Option {
+	return func(sr *Scheduler) {
+		sr.pools = pools
+	}
+}
+
+// Pools returns the utilization of resource pools by pool name
+func (sr *Scheduler) Pools() (pools map[string]PoolStats) {
+	sr.query(func() { pools = sr.poolStats() })
+	return
+}
+
+func (sr *Scheduler) poolStats() map[string]PoolStats {
+	pools := make(map[string]PoolStats, len(sr.pools))
+	for name, size := range sr.pools {
+		pools[name] = PoolStats{Size: size, Used: sr.poolUsage[name]}
+	}
+	for _, w := range sr.waiters {
+		for name := range w.job.Resources().Weights {
+			if p, ok := pools[name]; ok {
+				p.Waiting++
+				pools[name] = p
+			}
+		}
+	}
+	return pools
+}
+
+// validateResources checks that the job resource weights fit the pools, otherwise the job never runs
+func (sr *Scheduler) validateResources(j *job.Job) error {
+	for name, weight := range j.Resources().Weights {
+		size, ok := sr.pools[name]
+		if !ok {
+			return fmt.Errorf("job %v requires unknown resource pool %v", j.Id, name)
+		}
+		if weight > size {
+			return fmt.Errorf("job %v requires %d of resource pool %v, which size is %d", j.Id, weight, name, size)
+		}
+	}
+	return nil
+}
+
+// exhaustedPool returns the first of the job resource pools, which free capacity is less than the job weight
+func (sr *Scheduler) exhaustedPool(j *job.Job) (string, bool) {
+	for name, weight := range j.Resources().Weights {
+		if sr.poolUsage[name]+weight > sr.pools[name] {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// allocate takes the job weights from the resource pools
+func (sr *Scheduler) allocate(j *job.Job) {
+	for name, weight := range j.Resources().Weights {
+		sr.poolUsage[name] += weight
+	}
+}
+
+// free returns the job weights to the resource pools
+func (sr *Scheduler) free(j *job.Job) {
+	for name, weight := range j.Resources().Weights {
+		sr.poolUsage[name] -= weight
+		if sr.poolUsage[name] <= 0 {
+			delete(sr.poolUsage, name)
+		}
+	}
+}
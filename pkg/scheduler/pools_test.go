@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Tarick/tscheduler/pkg/job"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+)
+
+func TestPools(t *testing.T) {
+	sr := New(zap.NewNop().Sugar(), WithPools(map[string]int{"cpu": 3, "io": 1}))
+	started := make(chan string, 10)
+	release := make(chan struct{})
+	command := func(id string) func(context.Context, job.RunInfo) error {
+		return func(ctx context.Context, run job.RunInfo) error {
+			started <- id
+			<-release
+			return nil
+		}
+	}
+	jobs := []struct {
+		id        string
+		resources job.Resources
+	}{
+		{"compress", job.Resources{Weights: map[string]int{"cpu": 2}}},
+		{"encode", job.Resources{Weights: map[string]int{"cpu": 2}}},
+		{"index", job.Resources{Weights: map[string]int{"cpu": 2}, Policy: job.ResourcesSkip}},
+		{"copy", job.Resources{Weights: map[string]int{"cpu": 1, "io": 1}}},
+	}
+	for _, d := range jobs {
+		j, err := job.New(d.id, command(d.id), nil, job.WithResources(d.resources))
+		if err != nil {
+			t.Fatalf("Error creating job %v: %v", d.id, err)
+		}
+		if err := sr.AddJob(j); err != nil {
+			t.Fatalf("Error adding job %v: %v", d.id, err)
+		}
+		sr.startJob(j, time.Now())
+	}
+	// Copy fits the pool, while encode waits for compress
+	var got []string
+	for i := 0; i < 2; i++ {
+		got = append(got, <-started)
+	}
+	sort.Strings(got)
+	expectedPools := map[string]PoolStats{"cpu": {Size: 3, Used: 3, Waiting: 1}, "io": {Size: 1, Used: 1}}
+	if diff := cmp.Diff(sr.Pools(), expectedPools); diff != "" {
+		t.Errorf("Test for pools utilization failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", expectedPools, sr.Pools(), diff)
+	}
+	close(release)
+	for finished := 0; finished < 4; {
+		select {
+		case <-sr.wake:
+			sr.finishedMutex.Lock()
+			finished += len(sr.finished)
+			sr.finishedMutex.Unlock()
+			sr.processFinished()
+		case id := <-started:
+			got = append(got, id)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Runs haven't finished, started %v", got)
+		}
+	}
+	// Runs send their start before they finish
+	for len(started) != 0 {
+		got = append(got, <-started)
+	}
+	expected := []string{"compress", "copy", "encode"}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("Test for pools failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", expected, got, diff)
+	}
+	if stats := sr.Stats()["index"]; stats.Skipped != 1 {
+		t.Errorf("Index run must be skipped, got %v", stats)
+	}
+	expectedPools = map[string]PoolStats{"cpu": {Size: 3}, "io": {Size: 1}}
+	if diff := cmp.Diff(sr.Pools(), expectedPools); diff != "" {
+		t.Errorf("Test for released pools failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", expectedPools, sr.Pools(), diff)
+	}
+	for _, weights := range []map[string]int{{"gpu": 1}, {"cpu": 4}} {
+		j, err := job.New("heavy", command("heavy"), nil, job.WithResources(job.Resources{Weights: weights}))
+		if err != nil {
+			t.Fatalf("Error creating job: %v", err)
+		}
+		if err := sr.AddJob(j); err == nil {
+			t.Errorf("Job with resources %v, which don't fit the pools, must not be added", weights)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Tarick/tscheduler/pkg/job"
+)
+
+// Overrun policies define what to do with the waiting run, when its job fires again
+const (
+	// OverrunRun keeps the run waiting
+	OverrunRun = "run"
+	// OverrunSkip skips the waiting run in favor of the new one
+	OverrunSkip = "skip"
+)
+
+// QueueStats is the state of the queue of runs, waiting for locks, resources or workers
+type QueueStats struct {
+	// Workers is the number of running runs, MaxWorkers is their limit, 0 means no limit
+	Workers    int
+	MaxWorkers int
+	// Waiting is the number of waiting runs
+	Waiting int
+	// Started is the total number of started runs, WaitTime is the total time they have waited in the queue
+	Started  int
+	WaitTime time.Duration
+}
+
+// DefaultPriorityAging is the wait time, which raises the waiting run priority by one
+const DefaultPriorityAging = time.Minute
+
+// WithPriorityAging sets the wait time, which raises the waiting run priority by one, so low priority runs don't starve.
+// Zero disables aging.
+func WithPriorityAging(d time.Duration) Option {
+	return func(sr *Scheduler) {
+		sr.priorityAging = d
+	}
+}
+
+// WithMaxConcurrentJobs limits the number of simultaneously running jobs, other runs wait in the queue
+func WithMaxConcurrentJobs(n int) Option {
+	return func(sr *Scheduler) {
+		sr.maxWorkers = n
+	}
+}
+
+// WithOverrunPolicy sets the policy for the waiting run, when its job fires again, OverrunRun by default
+func WithOverrunPolicy(policy string) Option {
+	return func(sr *Scheduler) {
+		sr.overrunPolicy = policy
+	}
+}
+
+// ValidateOverrunPolicy checks the overrun policy name
+func ValidateOverrunPolicy(policy string) error {
+	switch policy {
+	case "", OverrunRun, OverrunSkip:
+		return nil
+	}
+	return fmt.Errorf("unknown overrun policy %s, must be one of %s, %s", policy, OverrunRun, OverrunSkip)
+}
+
+// Queue returns the state of the queue of waiting runs
+func (sr *Scheduler) Queue() (stats QueueStats) {
+	sr.query(func() { stats = sr.queueStats() })
+	return
+}
+
+func (sr *Scheduler) queueStats() QueueStats {
+	return QueueStats{
+		Workers:    sr.workers,
+		MaxWorkers: sr.maxWorkers,
+		Waiting:    len(sr.waiters),
+		Started:    sr.queueStarted,
+		WaitTime:   sr.queueWaitTime,
+	}
+}
+
+// priority returns the waiting run priority at t, raised by its wait time
+func (sr *Scheduler) priority(w waiter, t time.Time) int {
+	p := w.job.Priority()
+	if sr.priorityAging > 0 {
+		p += int(t.Sub(w.since) / sr.priorityAging)
+	}
+	return p
+}
+
+// sortWaiters orders the queue at t: higher priority first, then the earliest scheduled, then in order of arrival
+func (sr *Scheduler) sortWaiters(t time.Time) {
+	sort.SliceStable(sr.waiters, func(i, k int) bool {
+		a, b := sr.waiters[i], sr.waiters[k]
+		if pa, pb := sr.priority(a, t), sr.priority(b, t); pa != pb {
+			return pa > pb
+		}
+		return a.run.Scheduled.Before(b.run.Scheduled)
+	})
+}
+
+// checkOverrun applies the overrun policy to the waiting runs of the job, which fires again at t
+func (sr *Scheduler) checkOverrun(j *job.Job, t time.Time) {
+	if sr.overrunPolicy != OverrunSkip || sr.pending[j.Id] == 0 {
+		return
+	}
+	sr.logger.Warn("Job \"", j.Id, "\" fires at ", t, " while its ", sr.pending[j.Id], " runs still wait, skipping them")
+	sr.removeWaiters(j.Id, fmt.Errorf("run has waited past the next scheduled run at %v", t))
+}
@@ -0,0 +1,133 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Tarick/tscheduler/pkg/job"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+)
+
+func TestQueue(t *testing.T) {
+	sr := New(zap.NewNop().Sugar(), WithMaxConcurrentJobs(1), WithOverrunPolicy(OverrunSkip))
+	started := make(chan string, 10)
+	release := make(chan struct{})
+	command := func(id string) func(context.Context, job.RunInfo) error {
+		return func(ctx context.Context, run job.RunInfo) error {
+			started <- id
+			<-release
+			return nil
+		}
+	}
+	now := time.Now()
+	// Runs wait in order of their scheduled time, not the order of arrival
+	jobs := []struct {
+		id        string
+		scheduled time.Time
+	}{
+		{"first", now},
+		{"late", now.Add(-time.Minute)},
+		{"later", now.Add(-2 * time.Minute)},
+		{"overrun", now.Add(-3 * time.Minute)},
+	}
+	for _, d := range jobs {
+		j, err := job.New(d.id, command(d.id), nil)
+		if err != nil {
+			t.Fatalf("Error creating job %v: %v", d.id, err)
+		}
+		sr.AddJob(j)
+		sr.startJob(j, d.scheduled)
+	}
+	expectedQueue := QueueStats{Workers: 1, MaxWorkers: 1, Waiting: 3, Started: 1}
+	if diff := cmp.Diff(sr.Queue(), expectedQueue); diff != "" {
+		t.Errorf("Test for queue state failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", expectedQueue, sr.Queue(), diff)
+	}
+	sr.checkOverrun(sr.getJob("overrun"), now)
+	close(release)
+	var got []string
+	for finished := 0; finished < 4; {
+		select {
+		case <-sr.wake:
+			sr.finishedMutex.Lock()
+			finished += len(sr.finished)
+			sr.finishedMutex.Unlock()
+			sr.processFinished()
+		case id := <-started:
+			got = append(got, id)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Runs haven't finished, started %v", got)
+		}
+	}
+	// Runs send their start before they finish
+	for len(started) != 0 {
+		got = append(got, <-started)
+	}
+	expected := []string{"first", "later", "late"}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("Test for queue order failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", expected, got, diff)
+	}
+	if stats := sr.Stats()["overrun"]; stats.Skipped != 1 || stats.Waiting != 0 {
+		t.Errorf("Overrun run must be skipped, got %v", stats)
+	}
+	if queue := sr.Queue(); queue.Workers != 0 || queue.Waiting != 0 || queue.Started != 3 || queue.WaitTime == 0 {
+		t.Errorf("Queue must be empty with wait time recorded, got %v", queue)
+	}
+}
+
+func TestPriority(t *testing.T) {
+	sr := New(zap.NewNop().Sugar())
+	command := func(ctx context.Context, run job.RunInfo) error { return nil }
+	schedule, err := job.NewSchedule(job.ScheduleSpec{Text: "every day"})
+	if err != nil {
+		t.Fatalf("Error creating schedule: %v", err)
+	}
+	now := time.Now()
+	jobs := []struct {
+		id       string
+		priority int
+		since    time.Time
+	}{
+		{"low", 0, now.Add(-10 * time.Minute)},
+		{"normal", 1, now.Add(-time.Minute)},
+		{"high", 5, now},
+	}
+	for _, d := range jobs {
+		j, err := job.New(d.id, command, []job.Schedule{schedule}, job.WithPriority(d.priority))
+		if err != nil {
+			t.Fatalf("Error creating job %v: %v", d.id, err)
+		}
+		sr.AddJob(j)
+		j.SetNextRun(now)
+		sr.schedule(j)
+		sr.waiters = append(sr.waiters, waiter{job: j, run: job.RunInfo{Scheduled: now}, since: d.since})
+	}
+	testData := []struct {
+		aging    time.Duration
+		expected []string
+	}{
+		{0, []string{"high", "normal", "low"}},
+		// Low priority run has waited long enough to go first
+		{DefaultPriorityAging, []string{"low", "high", "normal"}},
+	}
+	for _, d := range testData {
+		sr.priorityAging = d.aging
+		sr.sortWaiters(now)
+		var got []string
+		for _, w := range sr.waiters {
+			got = append(got, w.job.Id)
+		}
+		if diff := cmp.Diff(got, d.expected); diff != "" {
+			t.Errorf("Test for priority aging %v failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", d.aging, d.expected, got, diff)
+		}
+	}
+	var got []string
+	for _, j := range sr.dueJobs(now.Add(48 * time.Hour)) {
+		got = append(got, j.Id)
+	}
+	expected := []string{"high", "normal", "low"}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("Test for due jobs order failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", expected, got, diff)
+	}
+}
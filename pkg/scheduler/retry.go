@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Tarick/tscheduler/pkg/job"
+)
+
+// pendingRetry is the failed run, waiting for its next attempt
+type pendingRetry struct {
+	job *job.Job
+	run job.RunInfo
+	at  time.Time
+}
+
+// scheduleRetry queues the next attempt of the failed run, if the job retry policy allows it. Reports if the retry is queued.
+// The retry, which doesn't come before the next scheduled run of the job, is dropped.
+func (sr *Scheduler) scheduleRetry(f finishedRun) bool {
+	policy := f.job.RetryPolicy()
+	// Cancelled runs are not retried on shutdown, replaced runs are superseded by the newer ones
+	if sr.jobsCtx.Err() != nil || errors.Is(f.err, errReplaced) || !policy.Retryable(f.run.Attempt, f.err) {
+		return false
+	}
+	at := f.at.Add(policy.Delay(f.run.Attempt))
+	if next := f.job.NextRun(); f.job.FixedDelay() == 0 && !next.IsZero() && !at.Before(next) {
+		sr.logger.Warn("Job \"", f.job.Id, "\" run ", f.run.ID, " is not retried, the next run at ", next, " comes before the retry at ", at)
+		return false
+	}
+	run := f.run
+	run.Attempt++
+	sr.retries = append(sr.retries, pendingRetry{job: f.job, run: run, at: at})
+	sr.logger.Info("Job \"", f.job.Id, "\" run ", run.ID, " attempt ", run.Attempt, " of ", policy.MaxAttempts, " scheduled at: ", at)
+	return true
+}
+
+// startRetries starts retries, which are due at t
+func (sr *Scheduler) startRetries(t time.Time) {
+	var pending []pendingRetry
+	for _, r := range sr.retries {
+		if r.at.After(t) {
+			pending = append(pending, r)
+			continue
+		}
+		sr.logger.Info("Retrying job ", r.job.Id, " run ", r.run.ID, ", attempt ", r.run.Attempt)
+		r.job.SetLastRun(t)
+		r.run.Started = sr.clock.Now()
+		sr.startRun(r.job, r.run)
+	}
+	sr.retries = pending
+}
+
+// hasRetry reports if the job has pending retry
+func (sr *Scheduler) hasRetry(j *job.Job) bool {
+	for _, r := range sr.retries {
+		if r.job == j {
+			return true
+		}
+	}
+	return false
+}
+
+// removeRetries drops pending retries of the job
+func (sr *Scheduler) removeRetries(id string) {
+	var pending []pendingRetry
+	for _, r := range sr.retries {
+		if r.job.Id != id {
+			pending = append(pending, r)
+		}
+	}
+	sr.retries = pending
+}
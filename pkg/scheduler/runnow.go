@@ -0,0 +1,64 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Tarick/tscheduler/pkg/job"
+)
+
+var (
+	// ErrNotRunning is returned for requests, which need the running scheduler
+	ErrNotRunning = errors.New("scheduler is not running")
+	// ErrUnknownJob is returned for the job ID, which is not registered in scheduler
+	ErrUnknownJob = errors.New("job does not exist")
+)
+
+// TriggerOptions are settings of the run, started on demand
+type TriggerOptions struct {
+	// Scheduled is the scheduled time, passed to the run, e.g. to rerun the job for the past schedule. Default is the request time.
+	Scheduled time.Time
+}
+
+// Trigger starts the job run right away, outside of the job schedule, and returns the run ID.
+// The run passes the job concurrency policy, locks, resources and the queue, like the scheduled runs,
+// so it may wait or be skipped. Dependent jobs start after the run, as in the scheduled workflow.
+// ErrNotRunning is returned, if scheduler is stopped before it takes the request.
+func (sr *Scheduler) Trigger(jobID string, opts TriggerOptions) (runID string, err error) {
+	commCh := make(chan struct{})
+	f := func() {
+		runID, err = sr.runNow(jobID, opts)
+		close(commCh)
+	}
+	select {
+	case sr.execF <- f:
+	case <-sr.doneChan():
+		return "", ErrNotRunning
+	}
+	// Scheduler goroutine runs the taken function before it checks for stop
+	<-commCh
+	return
+}
+
+func (sr *Scheduler) runNow(jobID string, opts TriggerOptions) (string, error) {
+	j := sr.getJob(jobID)
+	if j == nil {
+		return "", fmt.Errorf("%v %w", jobID, ErrUnknownJob)
+	}
+	run := job.RunInfo{
+		ID:        newRunID(),
+		Scheduled: opts.Scheduled,
+		Started:   sr.clock.Now(),
+		Attempt:   1,
+		Manual:    true,
+	}
+	if run.Scheduled.IsZero() {
+		run.Scheduled = run.Started
+	}
+	sr.logger.Info("Starting job ", j.Id, " run ", run.ID, " on demand, scheduled at: ", run.Scheduled)
+	j.SetLastRun(run.Started)
+	sr.startWorkflow(j, &run)
+	sr.startRun(j, run)
+	return run.ID, nil
+}
@@ -1,7 +1,11 @@
 package scheduler
 
 import (
+	"container/heap"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -18,13 +22,110 @@ type Logger interface {
 
 // Scheduler is main type for running jobs
 type Scheduler struct {
-	Jobs      []*job.Job
-	logger    Logger
-	running   bool
-	mutex     sync.Mutex
-	stop      chan struct{}
-	execF     chan func()
-	jobWaiter sync.WaitGroup
+	// jobs are registered jobs by ID, timers are scheduled ones ordered by the next run, dependents are IDs of jobs,
+	// which depend on the job, by job ID. Accessed by scheduler goroutine only, while it runs.
+	jobs       map[string]*jobEntry
+	timers     timerQueue
+	dependents map[string][]string
+	jobsSeq    uint64
+	logger     Logger
+	clock      Clock
+	running    bool
+	mutex      sync.Mutex
+	stop       chan struct{}
+	execF      chan func()
+	jobWaiter  sync.WaitGroup
+	// done is closed when scheduler goroutine exits and is replaced on start, protected by doneMutex
+	done      chan struct{}
+	doneMutex sync.Mutex
+	// runs holds number of running instances per job ID, accessed by scheduler goroutine only
+	runs map[string]int
+	// finished runs, which are not yet processed by scheduler goroutine, protected by finishedMutex.
+	// They are kept while scheduler is stopped and processed on start.
+	finished      []finishedRun
+	finishedMutex sync.Mutex
+	// wake notifies scheduler goroutine about finished runs
+	wake chan struct{}
+	// jobsCtx is parent context of all job runs, cancelled on Shutdown
+	jobsCtx    context.Context
+	jobsCancel context.CancelFunc
+	// history of finished runs
+	history HistoryStore
+	// state keeps the last fire time of jobs
+	state StateStore
+	// missed holds queued missed runs per job ID, accessed by scheduler goroutine only
+	missed map[string][]time.Time
+	// retries are failed runs waiting for the next attempt, accessed by scheduler goroutine only
+	retries []pendingRetry
+	// workflows are running workflow runs by ID, finishedWorkflows are the last finished ones, protected by workflowMutex
+	workflows         map[string]*WorkflowRun
+	finishedWorkflows []*WorkflowRun
+	workflowMutex     sync.Mutex
+	// queued runs, waiting by concurrency policy, cancels of running runs, which aren't replaced, in start order and
+	// the number of skipped runs per job ID, accessed by scheduler goroutine only
+	queued  map[string][]job.RunInfo
+	cancels map[string][]runCancel
+	skipped map[string]int
+	// onSkip is called for each run, skipped by scheduler
+	onSkip func(jobID string, run job.RunInfo, err error)
+	// pending holds number of admitted runs per job ID, which wait in the queue, accessed by scheduler goroutine only
+	pending map[string]int
+	// waiters are runs, waiting for locks, resources or workers in the queue order, accessed by scheduler goroutine only
+	waiters []waiter
+	// workers is the number of runs, holding locks and resources, maxWorkers is their limit. queueStarted and queueWaitTime
+	// are the total number of started runs and their wait time. Accessed by scheduler goroutine only.
+	workers       int
+	maxWorkers    int
+	overrunPolicy string
+	priorityAging time.Duration
+	queueStarted  int
+	queueWaitTime time.Duration
+	// lockHolders are runs, holding locks by lock name, lockWaitTime is the total wait time by lock name,
+	// accessed by scheduler goroutine only
+	lockHolders  map[string]lockHolder
+	lockWaitTime map[string]time.Duration
+	// pools are sizes of resource pools, poolUsage are weights of running runs by pool name, accessed by scheduler goroutine only
+	pools     map[string]int
+	poolUsage map[string]int
+	// jumpThreshold is the difference of the observed and expected wall time on wake up, taken as the wall clock jump
+	jumpThreshold time.Duration
+}
+
+// Option configures optional Scheduler settings
+type Option func(*Scheduler)
+
+// WithHistorySize sets the number of runs kept in memory history per job
+func WithHistorySize(n int) Option {
+	return func(sr *Scheduler) {
+		sr.history = NewMemoryHistoryStore(HistoryRetention{MaxRuns: n})
+	}
+}
+
+// WithHistoryStore sets the store of finished runs, e.g. FileHistoryStore to keep history over restarts.
+// Stores of this package apply MaxAge retention by the scheduler clock.
+func WithHistoryStore(store HistoryStore) Option {
+	return func(sr *Scheduler) {
+		sr.history = store
+	}
+}
+
+// WithStateStore sets the store of jobs last fire time, e.g. FileStateStore to start runs missed while scheduler was down
+func WithStateStore(store StateStore) Option {
+	return func(sr *Scheduler) {
+		sr.state = store
+	}
+}
+
+// finishedRun is sent by job goroutine when the job run has returned
+type finishedRun struct {
+	job *job.Job
+	run job.RunInfo
+	err error
+	at  time.Time
+	// status of the run record
+	status string
+	// held reports if the run has run and held its locks, resources and worker, i.e. it is not skipped by scheduler
+	held bool
 }
 
 // GetJobs returns the list of jobs, registered in scheduler
@@ -51,8 +152,9 @@ func (sr *Scheduler) IsRunning() bool {
 
 // getJobs returns the list of Jobs, registered in scheduler
 func (sr *Scheduler) getJobs() []job.Job {
-	var jobs = make([]job.Job, len(sr.Jobs))
-	for i, j := range sr.Jobs {
+	ordered := sr.orderedJobs()
+	var jobs = make([]job.Job, len(ordered))
+	for i, j := range ordered {
 		jobs[i] = *j
 	}
 	return jobs
@@ -66,12 +168,13 @@ func (sr *Scheduler) AddJob(j *job.Job) error {
 		var err error
 		sr.execF <- func() {
 			j := j
-			now := time.Now()
+			now := sr.clock.Now()
 			err = j.SetNextRun(now)
 			if err != nil {
 				err = fmt.Errorf("Job %v is not schedulable: %v", j.Id, err)
-			} else {
-				err = sr.addJob(j)
+			} else if err = sr.addJob(j); err == nil {
+				sr.checkMissed(j, now)
+				sr.startMissed(j)
 			}
 			close(commCh)
 		}
@@ -83,14 +186,20 @@ func (sr *Scheduler) AddJob(j *job.Job) error {
 	return sr.addJob(j)
 }
 
-// addJob adds job to the list, returns error on duplicate job name
+// addJob adds job to the list, returns error on duplicate job name or resources, which don't fit the pools
 func (sr *Scheduler) addJob(j *job.Job) error {
-	for _, je := range sr.Jobs {
-		if je.Id == j.Id {
-			return fmt.Errorf("%v job already exists", j.Id)
-		}
+	if _, ok := sr.jobs[j.Id]; ok {
+		return fmt.Errorf("%v job already exists", j.Id)
 	}
-	sr.Jobs = append(sr.Jobs, j)
+	if err := sr.validateResources(j); err != nil {
+		return err
+	}
+	sr.jobsSeq++
+	sr.jobs[j.Id] = &jobEntry{job: j, seq: sr.jobsSeq, index: -1}
+	for _, upstream := range j.DependsOn() {
+		sr.dependents[upstream] = append(sr.dependents[upstream], j.Id)
+	}
+	sr.schedule(j)
 	return nil
 }
 
@@ -114,33 +223,111 @@ func (sr *Scheduler) RemoveJob(name string) error {
 
 // RemoveJob removes job from Scheduler list. Returns error if nothing is removed.
 func (sr *Scheduler) removeJob(id string) error {
-	jobs := []*job.Job{}
-	for _, j := range sr.Jobs {
-		if j.Id == id {
-			continue
+	e, ok := sr.jobs[id]
+	if !ok {
+		return fmt.Errorf("%v job does not exist", id)
+	}
+	if e.index >= 0 {
+		heap.Remove(&sr.timers, e.index)
+	}
+	delete(sr.jobs, id)
+	for _, upstream := range e.job.DependsOn() {
+		var dependents []string
+		for _, dependent := range sr.dependents[upstream] {
+			if dependent != id {
+				dependents = append(dependents, dependent)
+			}
+		}
+		if len(dependents) == 0 {
+			delete(sr.dependents, upstream)
+		} else {
+			sr.dependents[upstream] = dependents
 		}
-		jobs = append(jobs, j)
 	}
-	if len(jobs) == len(sr.Jobs) {
-		return fmt.Errorf("%v job does not exist", id)
+	// Queued, waiting and retried runs of workflows are dropped with the job, which fails in these workflows
+	dropped := sr.queued[id]
+	for _, w := range sr.waiters {
+		if w.job.Id == id {
+			dropped = append(dropped, w.run)
+		}
+	}
+	for _, r := range sr.retries {
+		if r.job.Id == id {
+			dropped = append(dropped, r.run)
+		}
+	}
+	delete(sr.missed, id)
+	delete(sr.queued, id)
+	delete(sr.skipped, id)
+	sr.removeWaiters(id, nil)
+	sr.removeRetries(id)
+	for _, run := range dropped {
+		if run.WorkflowID == "" {
+			continue
+		}
+		sr.logger.Warn("Job \"", id, "\" run ", run.ID, " of workflow run ", run.WorkflowID, " is dropped with the job")
+		sr.finishWorkflowJob(finishedRun{job: e.job, run: run, at: sr.clock.Now(), status: RunFailed})
 	}
-	sr.Jobs = jobs
 	return nil
 }
 
 // NewScheduler constructs scheduler
-func New(l Logger) *Scheduler {
-	return &Scheduler{
-		logger: l,
-		stop:   make(chan struct{}),
-		execF:  make(chan func()),
+func New(l Logger, opts ...Option) *Scheduler {
+	jobsCtx, jobsCancel := context.WithCancel(context.Background())
+	sr := &Scheduler{
+		jobs:          make(map[string]*jobEntry),
+		dependents:    make(map[string][]string),
+		logger:        l,
+		clock:         systemClock{},
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+		execF:         make(chan func()),
+		runs:          make(map[string]int),
+		wake:          make(chan struct{}, 1),
+		jobsCtx:       jobsCtx,
+		jobsCancel:    jobsCancel,
+		history:       NewMemoryHistoryStore(HistoryRetention{}),
+		state:         NewMemoryStateStore(),
+		missed:        make(map[string][]time.Time),
+		workflows:     make(map[string]*WorkflowRun),
+		queued:        make(map[string][]job.RunInfo),
+		cancels:       make(map[string][]runCancel),
+		skipped:       make(map[string]int),
+		pending:       make(map[string]int),
+		lockHolders:   make(map[string]lockHolder),
+		lockWaitTime:  make(map[string]time.Duration),
+		poolUsage:     make(map[string]int),
+		priorityAging: DefaultPriorityAging,
+		jumpThreshold: DefaultClockJumpThreshold,
+	}
+	for _, opt := range opts {
+		opt(sr)
+	}
+	if store, ok := sr.history.(clockSetter); ok {
+		store.setClock(sr.clock)
 	}
+	// Scheduler isn't running until started
+	close(sr.done)
+	return sr
+}
+
+// History returns the last finished runs of the job, the newest first
+func (sr *Scheduler) History(jobID string) ([]RunRecord, error) {
+	return sr.history.History(jobID)
+}
+
+// Summary returns the job runs summary: the last success and failure, the number of failures in a row
+func (sr *Scheduler) Summary(jobID string) (RunSummary, error) {
+	return sr.history.Summary(jobID)
 }
 
 // Stop stops the scheduler scheduler, caller gets context to either wait for running jobs to finish or cancel and exit.
 func (sr *Scheduler) Stop() context.Context {
 	if sr.running {
 		sr.stop <- struct{}{}
+		// Scheduler goroutine holds the mutex until it exits
+		sr.mutex.Lock()
+		sr.mutex.Unlock()
 	}
 	ctx, cancel := context.WithCancel(context.Background())
 	go func() {
@@ -150,15 +337,182 @@ func (sr *Scheduler) Stop() context.Context {
 	return ctx
 }
 
-// starts job and adds to the wait list
-func (sr *Scheduler) startJob(j *job.Job) {
+// Shutdown stops the scheduler and cancels contexts of all running jobs, caller gets context to wait for the jobs to exit.
+// Jobs, started after Shutdown, get cancelled context right away.
+func (sr *Scheduler) Shutdown() context.Context {
+	sr.Stop()
+	sr.jobsCancel()
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		sr.jobWaiter.Wait()
+		cancel()
+	}()
+	return ctx
+}
+
+// newRunID generates random run identifier
+func newRunID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		// Unique enough as a fallback
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// starts the first attempt of the job run, scheduled at the time, and the workflow run if other jobs depend on the job
+func (sr *Scheduler) startJob(j *job.Job, scheduled time.Time) {
+	run := job.RunInfo{
+		ID:        newRunID(),
+		Scheduled: scheduled,
+		Started:   sr.clock.Now(),
+		Attempt:   1,
+	}
+	sr.startWorkflow(j, &run)
+	sr.startRun(j, run)
+}
+
+// execute starts job run and adds to the wait list, the run is finished with skip error right away if it is not nil.
+// Scheduler goroutine is notified when the job run returns.
+func (sr *Scheduler) execute(j *job.Job, run job.RunInfo, skip error) {
 	sr.jobWaiter.Add(1)
+	ctx, cancel := context.WithCancel(sr.jobsCtx)
+	// Skipped runs don't count as running
+	if skip == nil {
+		sr.runs[j.Id]++
+		sr.cancels[j.Id] = append(sr.cancels[j.Id], runCancel{runID: run.ID, cancel: cancel})
+	}
 	go func() {
 		defer sr.jobWaiter.Done()
-		j.Run()
+		err := skip
+		if err == nil {
+			err = j.Run(ctx, run)
+		}
+		// Run context is cancelled besides shutdown only to replace the run
+		if err != nil && ctx.Err() == context.Canceled && sr.jobsCtx.Err() == nil {
+			err = fmt.Errorf("%w: %v", errReplaced, err)
+		}
+		cancel()
+		finished := sr.clock.Now()
+		record := newRunRecord(j.Id, run, err, finished)
+		if err := sr.history.Add(record); err != nil {
+			sr.logger.Error("Failure recording job \"", j.Id, "\" run ", run.ID, " history: ", err)
+		}
+		name := run.ID
+		if run.Attempt > 1 {
+			name = fmt.Sprintf("%s (attempt %d)", run.ID, run.Attempt)
+		}
+		switch {
+		case errors.Is(err, job.ErrSkipped):
+			sr.logger.Warn("Job \"", j.Id, "\" run ", name, " skipped: ", err)
+		case err != nil:
+			sr.logger.Error("Job \"", j.Id, "\" run ", name, " failed: ", err)
+		default:
+			sr.logger.Info("Job \"", j.Id, "\" run ", name, " finished successfully")
+		}
+		sr.finishedMutex.Lock()
+		sr.finished = append(sr.finished, finishedRun{job: j, run: run, err: err, at: finished, status: record.Status, held: skip == nil})
+		sr.finishedMutex.Unlock()
+		select {
+		case sr.wake <- struct{}{}:
+		default:
+		}
 	}()
 }
 
+// processFinished handles finished job runs: starts queued runs, schedules retries of failed runs, continues workflows,
+// starts triggered jobs, starts the next missed run and schedules fixed delay jobs relative to their completion
+func (sr *Scheduler) processFinished() {
+	sr.finishedMutex.Lock()
+	finished := sr.finished
+	sr.finished = nil
+	sr.finishedMutex.Unlock()
+	for _, f := range finished {
+		if f.held {
+			sr.runs[f.job.Id]--
+			if sr.runs[f.job.Id] <= 0 {
+				delete(sr.runs, f.job.Id)
+			}
+		}
+		sr.removeCancel(f.job.Id, f.run.ID)
+		sr.release(f)
+		if sr.hasJob(f.job) {
+			sr.startQueued(f.job)
+		}
+		// Fixed delay job is scheduled, workflow continues and triggered jobs start after the last attempt
+		retried := sr.hasJob(f.job) && sr.scheduleRetry(f)
+		if !retried && f.run.WorkflowID != "" {
+			sr.finishWorkflowJob(f)
+		}
+		if !retried {
+			sr.startTriggered(f)
+		}
+		if retried || !sr.hasJob(f.job) {
+			continue
+		}
+		if f.job.FixedDelay() == 0 {
+			sr.startMissed(f.job)
+			continue
+		}
+		f.job.SetNextRunAfter(f.at)
+		sr.schedule(f.job)
+		sr.logger.Info("Job \"", f.job.Id, "\" finished, next run scheduled at: ", f.job.NextRun())
+	}
+}
+
+// checkMissed queues runs of the job, missed since its last fire, according to the job misfire policy.
+// The job, which has never fired, counts missed runs from now on.
+func (sr *Scheduler) checkMissed(j *job.Job, now time.Time) {
+	if j.FixedDelay() != 0 || !j.Scheduled() {
+		return
+	}
+	last, err := sr.state.LastFire(j.Id)
+	if err != nil {
+		sr.logger.Error("Failure getting job \"", j.Id, "\" last fire time: ", err)
+		return
+	}
+	if last.IsZero() {
+		sr.setLastFire(j, now)
+		return
+	}
+	missed := j.MissedRuns(last, now)
+	if len(missed) == 0 {
+		return
+	}
+	sr.logger.Warn("Job \"", j.Id, "\" missed runs since ", last, ", starting ", len(missed), " of them by misfire policy ", j.MisfirePolicy())
+	sr.missed[j.Id] = append(sr.missed[j.Id], missed...)
+	sr.setLastFire(j, missed[len(missed)-1])
+}
+
+// startMissed starts the next queued missed run of the job, missed runs don't overlap
+func (sr *Scheduler) startMissed(j *job.Job) {
+	missed := sr.missed[j.Id]
+	if len(missed) == 0 || sr.active(j.Id) > 0 || sr.hasRetry(j) {
+		return
+	}
+	if len(missed) == 1 {
+		delete(sr.missed, j.Id)
+	} else {
+		sr.missed[j.Id] = missed[1:]
+	}
+	sr.logger.Info("Starting missed run of job ", j.Id, ", scheduled at: ", missed[0])
+	j.SetLastRun(sr.clock.Now())
+	sr.startJob(j, missed[0])
+}
+
+// setLastFire records the last scheduled fire time of the job
+func (sr *Scheduler) setLastFire(j *job.Job, t time.Time) {
+	if err := sr.state.SetLastFire(j.Id, t); err != nil {
+		sr.logger.Error("Failure saving job \"", j.Id, "\" last fire time: ", err)
+	}
+}
+
+// hasJob reports if the job is still registered in scheduler
+func (sr *Scheduler) hasJob(j *job.Job) bool {
+	e, ok := sr.jobs[j.Id]
+	return ok && e.job == j
+}
+
 // Start scheduler asynchronously
 func (sr *Scheduler) Start() {
 	go sr.StartAndServe()
@@ -173,60 +527,118 @@ func (sr *Scheduler) StartAndServe() {
 	sr.mutex.Lock()
 	defer sr.mutex.Unlock()
 	sr.running = true
+	sr.doneMutex.Lock()
+	sr.done = make(chan struct{})
+	sr.doneMutex.Unlock()
 	sr.start()
 }
 
+// doneChan returns the channel, which is closed when scheduler goroutine exits
+func (sr *Scheduler) doneChan() <-chan struct{} {
+	sr.doneMutex.Lock()
+	defer sr.doneMutex.Unlock()
+	return sr.done
+}
+
+// query runs f in scheduler goroutine, or in the caller one if scheduler is not running or stops before it takes f
+func (sr *Scheduler) query(f func()) {
+	commCh := make(chan struct{})
+	select {
+	case sr.execF <- func() {
+		f()
+		close(commCh)
+	}:
+		// block until function exits
+		<-commCh
+	case <-sr.doneChan():
+		f()
+	}
+}
+
 // Actual scheduler start
 func (sr *Scheduler) start() {
 	log := sr.logger
 	log.Info("Started scheduler")
-	now := time.Now()
-	for _, j := range sr.Jobs {
+	sr.processFinished()
+	now := sr.clock.Now()
+	for _, j := range sr.orderedJobs() {
+		// Running fixed delay job is scheduled after it finishes
+		if j.FixedDelay() != 0 && (sr.active(j.Id) > 0 || sr.hasRetry(j)) {
+			j.ResetNextRun()
+			sr.schedule(j)
+			continue
+		}
 		if err := j.SetNextRun(now); err != nil {
 			log.Warn("Job \"", j.Id, "\" will not be scheduled due to error: ", err)
 		}
+		sr.schedule(j)
+		sr.checkMissed(j, now)
+		sr.startMissed(j)
 	}
-	var timer *time.Timer
+	var timer Timer
 	for {
 		wakeUpAt, err := sr.getWakeUpTime(now)
-		if err != nil {
+		// Running fixed delay jobs are scheduled when they finish
+		if err != nil && len(sr.runs) == 0 && len(sr.pending) == 0 {
 			log.Warn("Can't schedule next wakeup, ", err)
 		}
 		log.Debug("Next wake up at: ", wakeUpAt)
-		timer = time.NewTimer(wakeUpAt.Sub(now))
+		// Reference readings of the wall and monotonic clocks to detect the wall clock jump on wake up
+		sleptAt, sleptElapsed := sr.clock.Now(), sr.clock.Elapsed()
+		timer = sr.clock.NewTimer(wakeUpAt.Sub(now))
 		select {
-		case now = <-timer.C:
+		case now = <-timer.C():
 			sr.logger.Debug("Scheduler woke up")
-			for _, j := range sr.Jobs {
-				if j.NextRun().IsZero() {
-					continue
-				}
-				if j.NextRun().Before(now) {
-					j.SetLastRun(now)
-					log.Info("Starting job ", j.Id, ", scheduled at: ", j.NextRun, ", current time: ", j.LastRun)
-					sr.startJob(j)
-					if err := j.SetNextRun(now); err != nil {
-						log.Warn("Job \"", j.Id, "\" will not be scheduled further due to scheduling error: ", err)
-					} else {
-						log.Info("Job \"", j.Id, "\" next run scheduled at: ", j.NextRun)
-					}
-				}
-			}
+			sr.checkClockJump(sleptAt, sleptElapsed)
+			sr.tick(now)
 		case <-sr.stop:
 			timer.Stop()
 			sr.logger.Info("Scheduler stopped")
 			sr.running = false
+			sr.doneMutex.Lock()
+			close(sr.done)
+			sr.doneMutex.Unlock()
 			return
+		case <-sr.wake:
+			timer.Stop()
+			sr.checkClockJump(sleptAt, sleptElapsed)
+			sr.processFinished()
+			now = sr.clock.Now()
 		// execute any function, passed to the scheduler
 		case f := <-sr.execF:
 			timer.Stop()
+			sr.checkClockJump(sleptAt, sleptElapsed)
 			f()
-			now = time.Now()
+			now = sr.clock.Now()
 		}
 	}
 
 }
 
+// tick starts retries and jobs, which are due at t, and schedules their next runs
+func (sr *Scheduler) tick(t time.Time) {
+	log := sr.logger
+	sr.startRetries(t)
+	for _, j := range sr.dueJobs(t) {
+		j.SetLastRun(t)
+		log.Info("Starting job ", j.Id, ", scheduled at: ", j.NextRun(), ", current time: ", j.LastRun())
+		sr.setLastFire(j, j.NextRun())
+		sr.checkOverrun(j, j.NextRun())
+		sr.startJob(j, j.NextRun())
+		// Due jobs are out of the timer queue until rescheduled
+		if j.FixedDelay() != 0 {
+			j.ResetNextRun()
+			continue
+		}
+		if err := j.SetNextRun(t); err != nil {
+			log.Warn("Job \"", j.Id, "\" will not be scheduled further due to scheduling error: ", err)
+		} else {
+			log.Info("Job \"", j.Id, "\" next run scheduled at: ", j.NextRun())
+		}
+		sr.schedule(j)
+	}
+}
+
 // getWakeUpTime returns time for scheduler to wakeup - the earliest running job
 func (sr *Scheduler) getWakeUpTime(t time.Time) (wakeUp time.Time, err error) {
 	// Create initial wakeup value that is too far in the future (10 years)
@@ -234,14 +646,17 @@ func (sr *Scheduler) getWakeUpTime(t time.Time) (wakeUp time.Time, err error) {
 	initialWakeUp := t.AddDate(10, 0, 0)
 	wakeUp = initialWakeUp
 
-	for _, j := range sr.Jobs {
-		if j.NextRun().After(t) && j.NextRun().Before(wakeUp) {
-			wakeUp = j.NextRun()
-			err = nil
+	for _, r := range sr.retries {
+		if r.at.Before(wakeUp) {
+			wakeUp = r.at
 		}
 	}
+	// Overdue jobs (e.g. fixed delay ones, scheduled while processing) wake up scheduler right away
+	if len(sr.timers) != 0 && sr.timers[0].job.NextRun().Before(wakeUp) {
+		wakeUp = sr.timers[0].job.NextRun()
+	}
 	if wakeUp.Equal(initialWakeUp) {
-		return wakeUp, fmt.Errorf("couldn't find next sleep time for jobs:\n %v", sr.Jobs)
+		return wakeUp, fmt.Errorf("couldn't find next sleep time, none of %d jobs is scheduled", len(sr.jobs))
 	}
 	return wakeUp, nil
 }
@@ -0,0 +1,398 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Tarick/tscheduler/pkg/job"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// testRuns records starts of job runs as "job scheduled-time"
+type testRuns chan string
+
+func (r testRuns) command(id string) func(context.Context, job.RunInfo) error {
+	return func(ctx context.Context, run job.RunInfo) error {
+		r <- id + " " + run.Scheduled.Format("15:04:05")
+		return nil
+	}
+}
+
+// receive returns n started runs, sorted as runs of the same wake up start in any order
+func (r testRuns) receive(t *testing.T, n int) []string {
+	t.Helper()
+	got := []string{}
+	for len(got) != n {
+		select {
+		case run := <-r:
+			got = append(got, run)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for runs, got %v of %d", got, n)
+		}
+	}
+	sort.Strings(got)
+	return got
+}
+
+// newTestJob creates job, which records its runs
+func newTestJob(t *testing.T, runs testRuns, id, text string, opts ...job.Option) *job.Job {
+	t.Helper()
+	var schedules []job.Schedule
+	if text != "" {
+		schedule, err := job.NewSchedule(job.ScheduleSpec{Text: text})
+		if err != nil {
+			t.Fatalf("Error creating schedule %v: %v", text, err)
+		}
+		schedules = append(schedules, schedule)
+	}
+	j, err := job.New(id, runs.command(id), schedules, opts...)
+	if err != nil {
+		t.Fatalf("Error creating job %v: %v", id, err)
+	}
+	return j
+}
+
+// startTestScheduler starts scheduler with the fake clock and waits until it sleeps
+func startTestScheduler(t *testing.T, clock *FakeClock, jobs ...*job.Job) *Scheduler {
+	t.Helper()
+	sr := New(zap.NewNop().Sugar(), WithClock(clock))
+	for _, j := range jobs {
+		if err := sr.AddJob(j); err != nil {
+			t.Fatalf("Error adding job %v: %v", j.Id, err)
+		}
+	}
+	sr.Start()
+	clock.BlockUntil(1)
+	t.Cleanup(func() { sr.Stop() })
+	return sr
+}
+
+// advance moves the clock and waits until scheduler has started due runs and sleeps again
+func advance(clock *FakeClock, d time.Duration) {
+	clock.BlockUntil(1)
+	clock.Advance(d)
+	clock.BlockUntil(1)
+}
+
+// waitIdle waits until scheduler has processed all finished runs
+func waitIdle(t *testing.T, sr *Scheduler) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		running := 0
+		for _, stats := range sr.Stats() {
+			running += stats.Running
+		}
+		if running == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %d running jobs", running)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+var testStart = time.Date(2021, 3, 1, 10, 0, 0, 0, time.Local)
+
+func TestWakeUps(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	runs := make(testRuns, 10)
+	sr := startTestScheduler(t, clock,
+		newTestJob(t, runs, "minutely", "every minute"),
+		newTestJob(t, runs, "two", "every 2 minutes"),
+		newTestJob(t, runs, "delay", "", job.WithFixedDelay(90*time.Second)),
+	)
+	// Fixed delay job is scheduled after its run finishes, all due jobs start on the same wake up
+	expected := [][]string{
+		{"delay 10:00:01"},
+		{"minutely 10:01:00"},
+		{},
+		{"delay 10:02:00", "minutely 10:02:00", "two 10:02:00"},
+		{},
+		{"minutely 10:03:00"},
+		{"delay 10:03:30"},
+		{"minutely 10:04:00", "two 10:04:00"},
+	}
+	for i, e := range expected {
+		advance(clock, 30*time.Second)
+		got := runs.receive(t, len(e))
+		if diff := cmp.Diff(got, e); diff != "" {
+			t.Errorf("Test for wake up %d at %v failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", i+1, clock.Now(), e, got, diff)
+		}
+		waitIdle(t, sr)
+	}
+}
+
+func TestAddRemoveWhileRunning(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	runs := make(testRuns, 10)
+	sr := startTestScheduler(t, clock, newTestJob(t, runs, "removed", "every minute"))
+	if err := sr.AddJob(newTestJob(t, runs, "added", "every minute")); err != nil {
+		t.Fatalf("Error adding job: %v", err)
+	}
+	if err := sr.AddJob(newTestJob(t, runs, "added", "every hour")); err == nil {
+		t.Error("Expected error adding duplicate job")
+	}
+	if err := sr.RemoveJob("removed"); err != nil {
+		t.Fatalf("Error removing job: %v", err)
+	}
+	if err := sr.RemoveJob("removed"); err == nil {
+		t.Error("Expected error removing unknown job")
+	}
+	var ids []string
+	for _, j := range sr.GetJobs() {
+		ids = append(ids, j.Id)
+	}
+	if diff := cmp.Diff(ids, []string{"added"}); diff != "" {
+		t.Errorf("Test for registered jobs failed!\nDIFF: %v\n", diff)
+	}
+	advance(clock, time.Minute)
+	expected := []string{"added 10:01:00"}
+	if diff := cmp.Diff(runs.receive(t, 1), expected); diff != "" {
+		t.Errorf("Test for runs of added job failed!\nDIFF: %v\n", diff)
+	}
+	waitIdle(t, sr)
+	advance(clock, time.Minute)
+	expected = []string{"added 10:02:00"}
+	if diff := cmp.Diff(runs.receive(t, 1), expected); diff != "" {
+		t.Errorf("Test for runs of added job failed!\nDIFF: %v\n", diff)
+	}
+}
+
+func TestStopStart(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	runs := make(testRuns, 10)
+	sr := startTestScheduler(t, clock,
+		newTestJob(t, runs, "skip", "every minute"),
+		newTestJob(t, runs, "once", "every minute", job.WithMisfirePolicy(job.MisfirePolicy{Policy: job.MisfireRunOnce})),
+	)
+	// Stopped scheduler is started again after all its runs have finished
+	<-sr.Stop().Done()
+	if sr.IsRunning() {
+		t.Fatal("Scheduler is running after stop")
+	}
+	// Stopped scheduler doesn't wait for wake ups
+	if n := clock.Timers(); n != 0 {
+		t.Fatalf("Stopped scheduler has %d timers", n)
+	}
+	clock.Advance(3*time.Minute + 30*time.Second)
+	select {
+	case run := <-runs:
+		t.Fatalf("Run %v started while scheduler was stopped", run)
+	default:
+	}
+	// Runs, missed while stopped, start by misfire policy
+	sr.Start()
+	expected := []string{"once 10:03:00"}
+	if diff := cmp.Diff(runs.receive(t, 1), expected); diff != "" {
+		t.Errorf("Test for missed runs failed!\nDIFF: %v\n", diff)
+	}
+	waitIdle(t, sr)
+	advance(clock, 30*time.Second)
+	expected = []string{"once 10:04:00", "skip 10:04:00"}
+	if diff := cmp.Diff(runs.receive(t, 2), expected); diff != "" {
+		t.Errorf("Test for runs after restart failed!\nDIFF: %v\n", diff)
+	}
+}
+
+func TestClockJump(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	runs := make(testRuns, 20)
+	sr := startTestScheduler(t, clock,
+		newTestJob(t, runs, "skip", "every minute"),
+		newTestJob(t, runs, "once", "every minute", job.WithMisfirePolicy(job.MisfirePolicy{Policy: job.MisfireRunOnce})),
+		newTestJob(t, runs, "all", "every minute", job.WithMisfirePolicy(job.MisfirePolicy{Policy: job.MisfireRunAll, MaxRuns: 3})),
+	)
+	// Timer fires late by the wall clock after suspend, the run, timer was set for, starts as usual,
+	// runs, missed during suspend, start by misfire policy
+	clock.Set(testStart.Add(30 * time.Minute))
+	advance(clock, time.Minute)
+	expected := []string{
+		"all 10:01:00", "all 10:29:00", "all 10:30:00", "all 10:31:00",
+		"once 10:01:00", "once 10:31:00",
+		"skip 10:01:00",
+	}
+	if diff := cmp.Diff(runs.receive(t, len(expected)), expected); diff != "" {
+		t.Errorf("Test for runs after suspend failed!\nDIFF: %v\n", diff)
+	}
+	waitIdle(t, sr)
+	advance(clock, time.Minute)
+	expected = []string{"all 10:32:00", "once 10:32:00", "skip 10:32:00"}
+	if diff := cmp.Diff(runs.receive(t, len(expected)), expected); diff != "" {
+		t.Errorf("Test for runs after suspend failed!\nDIFF: %v\n", diff)
+	}
+	waitIdle(t, sr)
+	// Timer fires early by the wall clock after the clock step backward, runs already fired at don't repeat
+	clock.Set(testStart.Add(2 * time.Minute))
+	advance(clock, time.Minute)
+	advance(clock, 30*time.Minute)
+	expected = []string{"all 10:33:00", "once 10:33:00", "skip 10:33:00"}
+	if diff := cmp.Diff(runs.receive(t, len(expected)), expected); diff != "" {
+		t.Errorf("Test for runs after clock step backward failed!\nDIFF: %v\n", diff)
+	}
+	waitIdle(t, sr)
+	select {
+	case run := <-runs:
+		t.Errorf("Unexpected run %v after clock step backward", run)
+	default:
+	}
+}
+
+func TestTrigger(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	runs := make(testRuns, 10)
+	release := make(chan struct{})
+	blocking, err := job.New("blocking", func(ctx context.Context, run job.RunInfo) error {
+		runs <- "blocking " + run.Scheduled.Format("15:04:05")
+		<-release
+		return nil
+	}, nil)
+	if err != nil {
+		t.Fatalf("Error creating job: %v", err)
+	}
+	sr := New(zap.NewNop().Sugar(), WithClock(clock))
+	sr.AddJob(newTestJob(t, runs, "hourly", "every hour", job.WithConcurrencyPolicy(job.ConcurrencyPolicy{})))
+	sr.AddJob(blocking)
+	if _, err := sr.Trigger("hourly", TriggerOptions{}); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Expected error %v running job of stopped scheduler, got %v", ErrNotRunning, err)
+	}
+	sr.Start()
+	clock.BlockUntil(1)
+	defer sr.Stop()
+	if _, err := sr.Trigger("unknown", TriggerOptions{}); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("Expected error %v running unknown job, got %v", ErrUnknownJob, err)
+	}
+	// Run is scheduled at the request time, unless the time is set
+	if _, err := sr.Trigger("hourly", TriggerOptions{}); err != nil {
+		t.Fatalf("Error running job: %v", err)
+	}
+	if _, err := sr.Trigger("hourly", TriggerOptions{Scheduled: testStart.Add(-time.Hour)}); err != nil {
+		t.Fatalf("Error running job: %v", err)
+	}
+	expected := []string{"hourly 09:00:00", "hourly 10:00:00"}
+	if diff := cmp.Diff(runs.receive(t, 2), expected); diff != "" {
+		t.Errorf("Test for runs on demand failed!\nDIFF: %v\n", diff)
+	}
+	// Runs on demand follow the job concurrency policy
+	first, err := sr.Trigger("blocking", TriggerOptions{})
+	if err != nil {
+		t.Fatalf("Error running job: %v", err)
+	}
+	runs.receive(t, 1)
+	skipped, err := sr.Trigger("blocking", TriggerOptions{})
+	if err != nil {
+		t.Fatalf("Error running job: %v", err)
+	}
+	close(release)
+	waitIdle(t, sr)
+	history, err := sr.History("blocking")
+	if err != nil {
+		t.Fatalf("Error getting history: %v", err)
+	}
+	statuses := make(map[string]string)
+	for _, r := range history {
+		if !r.Manual {
+			t.Errorf("Run %v is not recorded as manual", r.RunID)
+		}
+		statuses[r.RunID] = r.Status
+	}
+	expectedStatuses := map[string]string{first: RunSuccess, skipped: RunSkipped}
+	if diff := cmp.Diff(statuses, expectedStatuses); diff != "" {
+		t.Errorf("Test for runs on demand statuses failed!\nDIFF: %v\n", diff)
+	}
+}
+
+func TestTriggerStop(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	noop, err := job.New("noop", func(ctx context.Context, run job.RunInfo) error { return nil }, nil)
+	if err != nil {
+		t.Fatalf("Error creating job: %v", err)
+	}
+	sr := New(zap.NewNop().Sugar(), WithClock(clock))
+	sr.AddJob(noop)
+	sr.Start()
+	clock.BlockUntil(1)
+	// Requests, racing scheduler stop, return instead of blocking
+	returned := make(chan error)
+	go func() {
+		for {
+			if _, err := sr.Trigger("noop", TriggerOptions{}); err != nil {
+				returned <- err
+				return
+			}
+		}
+	}()
+	sr.Stop()
+	select {
+	case err := <-returned:
+		if !errors.Is(err, ErrNotRunning) {
+			t.Errorf("Expected error %v running job of stopping scheduler, got %v", ErrNotRunning, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Run on demand is blocked by scheduler stop")
+	}
+	<-sr.Stop().Done()
+}
+
+func TestLateTimer(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	runs := make(testRuns, 10)
+	core, logs := observer.New(zap.WarnLevel)
+	sr := New(zap.New(core).Sugar(), WithClock(clock))
+	sr.AddJob(newTestJob(t, runs, "all", "every minute", job.WithMisfirePolicy(job.MisfirePolicy{Policy: job.MisfireRunAll, MaxRuns: 3})))
+	sr.Start()
+	clock.BlockUntil(1)
+	t.Cleanup(func() { sr.Stop() })
+	// Timer fires late by the elapsed time as well, e.g. the scheduler was busy, that isn't the clock jump
+	advance(clock, 5*time.Minute)
+	expected := []string{"all 10:01:00"}
+	if diff := cmp.Diff(runs.receive(t, 1), expected); diff != "" {
+		t.Errorf("Test for late timer failed!\nDIFF: %v\n", diff)
+	}
+	waitIdle(t, sr)
+	advance(clock, time.Minute)
+	expected = []string{"all 10:06:00"}
+	if diff := cmp.Diff(runs.receive(t, 1), expected); diff != "" {
+		t.Errorf("Test for runs after late timer failed!\nDIFF: %v\n", diff)
+	}
+	waitIdle(t, sr)
+	select {
+	case run := <-runs:
+		t.Errorf("Unexpected run %v after late timer", run)
+	default:
+	}
+	for _, entry := range logs.All() {
+		t.Errorf("Unexpected warning after late timer: %v", entry.Message)
+	}
+}
+
+func TestLateWakeUp(t *testing.T) {
+	clock := NewFakeClock(testStart)
+	runs := make(testRuns, 10)
+	sr := startTestScheduler(t, clock, newTestJob(t, runs, "skip", "every minute"))
+	// Timer fires late by more than the jump threshold, but the run is due, it starts despite skip misfire policy
+	clock.Set(testStart.Add(30 * time.Second))
+	advance(clock, time.Minute)
+	expected := []string{"skip 10:01:00"}
+	if diff := cmp.Diff(runs.receive(t, 1), expected); diff != "" {
+		t.Errorf("Test for late wake up failed!\nDIFF: %v\n", diff)
+	}
+	waitIdle(t, sr)
+	advance(clock, 30*time.Second)
+	expected = []string{"skip 10:02:00"}
+	if diff := cmp.Diff(runs.receive(t, 1), expected); diff != "" {
+		t.Errorf("Test for runs after late wake up failed!\nDIFF: %v\n", diff)
+	}
+	waitIdle(t, sr)
+	select {
+	case run := <-runs:
+		t.Errorf("Unexpected run %v after late wake up", run)
+	default:
+	}
+}
@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// StateStore keeps the last scheduled fire time of jobs, so runs missed while scheduler was down are detected.
+// Implementations must be safe for concurrent use.
+type StateStore interface {
+	// LastFire returns the last scheduled fire time of the job, zero time if unknown
+	LastFire(jobID string) (time.Time, error)
+	// SetLastFire records the last scheduled fire time of the job
+	SetLastFire(jobID string, t time.Time) error
+}
+
+// memoryStateStore keeps state in memory only, so misfires are detected on pause and resume only
+type memoryStateStore struct {
+	mutex     sync.Mutex
+	lastFires map[string]time.Time
+}
+
+// NewMemoryStateStore creates StateStore, which loses the state on restart
+func NewMemoryStateStore() StateStore {
+	return &memoryStateStore{lastFires: make(map[string]time.Time)}
+}
+
+func (s *memoryStateStore) LastFire(jobID string) (time.Time, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.lastFires[jobID], nil
+}
+
+func (s *memoryStateStore) SetLastFire(jobID string, t time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.lastFires[jobID] = t
+	return nil
+}
+
+// FileStateStore keeps state in JSON file, which is replaced on every change
+type FileStateStore struct {
+	path      string
+	mutex     sync.Mutex
+	lastFires map[string]time.Time
+}
+
+// NewFileStateStore loads state from the file at path, the file is created on the first change
+func NewFileStateStore(path string) (*FileStateStore, error) {
+	s := &FileStateStore{path: path, lastFires: make(map[string]time.Time)}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failure reading state file %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, &s.lastFires); err != nil {
+		return nil, fmt.Errorf("failure parsing state file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *FileStateStore) LastFire(jobID string) (time.Time, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.lastFires[jobID], nil
+}
+
+func (s *FileStateStore) SetLastFire(jobID string, t time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.lastFires[jobID] = t
+	data, err := json.MarshalIndent(s.lastFires, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := writeFileSync(tmp, data); err != nil {
+		return fmt.Errorf("failure writing state file %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failure replacing state file %s: %w", s.path, err)
+	}
+	return nil
+}
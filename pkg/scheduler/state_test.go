@@ -0,0 +1,29 @@
+package scheduler
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileStateStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	s, err := NewFileStateStore(path)
+	if err != nil {
+		t.Fatalf("Error creating state store: %v", err)
+	}
+	fired := time.Date(2021, 3, 1, 3, 0, 0, 0, time.UTC)
+	if err := s.SetLastFire("test", fired); err != nil {
+		t.Fatalf("Error saving state: %v", err)
+	}
+	s, err = NewFileStateStore(path)
+	if err != nil {
+		t.Fatalf("Error loading state store: %v", err)
+	}
+	if got, _ := s.LastFire("test"); !got.Equal(fired) {
+		t.Errorf("Test for state store failed!\nEXPECTED: \n %v\nNEW: \n %v\n", fired, got)
+	}
+	if got, _ := s.LastFire("missing"); !got.IsZero() {
+		t.Errorf("Last fire of unknown job must be zero, got %v", got)
+	}
+}
@@ -0,0 +1,94 @@
+package scheduler
+
+import (
+	"container/heap"
+	"sort"
+	"time"
+
+	"github.com/Tarick/tscheduler/pkg/job"
+)
+
+// jobEntry is the registered job with its position in the timer queue
+type jobEntry struct {
+	job *job.Job
+	// seq is the registration order of the job
+	seq uint64
+	// index in the timer queue, -1 if the job is not scheduled
+	index int
+}
+
+// timerQueue is min-heap of scheduled jobs by their next run, implements heap.Interface
+type timerQueue []*jobEntry
+
+func (q timerQueue) Len() int { return len(q) }
+
+func (q timerQueue) Less(i, k int) bool {
+	if a, b := q[i].job.NextRun(), q[k].job.NextRun(); !a.Equal(b) {
+		return a.Before(b)
+	}
+	return q[i].seq < q[k].seq
+}
+
+func (q timerQueue) Swap(i, k int) {
+	q[i], q[k] = q[k], q[i]
+	q[i].index = i
+	q[k].index = k
+}
+
+func (q *timerQueue) Push(x interface{}) {
+	e := x.(*jobEntry)
+	e.index = len(*q)
+	*q = append(*q, e)
+}
+
+func (q *timerQueue) Pop() interface{} {
+	old := *q
+	e := old[len(old)-1]
+	old[len(old)-1] = nil
+	e.index = -1
+	*q = old[:len(old)-1]
+	return e
+}
+
+// schedule updates the job position in the timer queue after its next run has changed.
+// Next run of registered jobs must be changed by scheduler goroutine only, followed by schedule.
+func (sr *Scheduler) schedule(j *job.Job) {
+	e, ok := sr.jobs[j.Id]
+	if !ok || e.job != j {
+		return
+	}
+	switch {
+	case j.NextRun().IsZero():
+		if e.index >= 0 {
+			heap.Remove(&sr.timers, e.index)
+		}
+	case e.index >= 0:
+		heap.Fix(&sr.timers, e.index)
+	default:
+		heap.Push(&sr.timers, e)
+	}
+}
+
+// dueJobs removes jobs, due at t, from the timer queue and returns them, higher priority first
+func (sr *Scheduler) dueJobs(t time.Time) []*job.Job {
+	var due []*job.Job
+	for len(sr.timers) != 0 && !sr.timers[0].job.NextRun().After(t) {
+		due = append(due, heap.Pop(&sr.timers).(*jobEntry).job)
+	}
+	sort.SliceStable(due, func(i, k int) bool { return due[i].Priority() > due[k].Priority() })
+	return due
+}
+
+// orderedJobs returns registered jobs in order of registration
+func (sr *Scheduler) orderedJobs() []*job.Job {
+	entries := make([]*jobEntry, 0, len(sr.jobs))
+	for _, e := range sr.jobs {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, k int) bool { return entries[i].seq < entries[k].seq })
+	jobs := make([]*job.Job, len(entries))
+	for i, e := range entries {
+		jobs[i] = e.job
+	}
+	return jobs
+}
@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Tarick/tscheduler/pkg/job"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+)
+
+// newFixedDelayJobs creates scheduler with n fixed delay jobs, job i is due i+1 seconds after t
+func newFixedDelayJobs(tb testing.TB, n int, t time.Time) *Scheduler {
+	sr := New(zap.NewNop().Sugar(), WithHistorySize(1))
+	command := func(ctx context.Context, run job.RunInfo) error { return nil }
+	for i := 0; i < n; i++ {
+		j, err := job.New(fmt.Sprintf("job%d", i), command, nil, job.WithFixedDelay(time.Duration(i+1)*time.Second))
+		if err != nil {
+			tb.Fatalf("Error creating job %d: %v", i, err)
+		}
+		if err := sr.AddJob(j); err != nil {
+			tb.Fatalf("Error adding job %d: %v", i, err)
+		}
+		j.SetNextRunAfter(t)
+		sr.schedule(j)
+	}
+	return sr
+}
+
+func TestTimerQueue(t *testing.T) {
+	now := time.Now()
+	sr := newFixedDelayJobs(t, 5, now)
+	wakeUp, err := sr.getWakeUpTime(now)
+	if err != nil {
+		t.Fatalf("Error getting wake up time: %v", err)
+	}
+	if expected := now.Add(time.Second); !wakeUp.Equal(expected) {
+		t.Errorf("Wake up time is %v, expected %v", wakeUp, expected)
+	}
+	// Rescheduled and removed jobs change their place in the queue
+	if err := sr.removeJob("job1"); err != nil {
+		t.Fatalf("Error removing job: %v", err)
+	}
+	j := sr.getJob("job0")
+	j.SetNextRunAfter(now.Add(time.Minute))
+	sr.schedule(j)
+	j = sr.getJob("job4")
+	j.ResetNextRun()
+	sr.schedule(j)
+
+	var got []string
+	for _, j := range sr.dueJobs(now.Add(time.Hour)) {
+		got = append(got, j.Id)
+	}
+	expected := []string{"job2", "job3", "job0"}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("Test for due jobs failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", expected, got, diff)
+	}
+	if _, err := sr.getWakeUpTime(now); err == nil {
+		t.Error("Expected error getting wake up time without scheduled jobs")
+	}
+	got = nil
+	for _, j := range sr.getJobs() {
+		got = append(got, j.Id)
+	}
+	expected = []string{"job0", "job2", "job3", "job4"}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("Test for jobs order failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", expected, got, diff)
+	}
+}
+
+// BenchmarkTick measures scheduler wake up with one due job: finding the wake up time,
+// starting the job and rescheduling it after it finishes.
+func BenchmarkTick(b *testing.B) {
+	for _, n := range []int{1000, 100000} {
+		b.Run(fmt.Sprintf("jobs=%d", n), func(b *testing.B) {
+			sr := newFixedDelayJobs(b, n, time.Now())
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				wakeUp, err := sr.getWakeUpTime(time.Now())
+				if err != nil {
+					b.Fatalf("Error getting wake up time: %v", err)
+				}
+				sr.tick(wakeUp.Add(time.Nanosecond))
+				sr.jobWaiter.Wait()
+				sr.processFinished()
+			}
+		})
+	}
+}
+
+// BenchmarkAddRemoveJob measures registering and removing a job next to 100k others
+func BenchmarkAddRemoveJob(b *testing.B) {
+	now := time.Now()
+	sr := newFixedDelayJobs(b, 100000, now)
+	j, err := job.New("added", func(ctx context.Context, run job.RunInfo) error { return nil }, nil, job.WithFixedDelay(time.Minute))
+	if err != nil {
+		b.Fatalf("Error creating job: %v", err)
+	}
+	j.SetNextRunAfter(now)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := sr.addJob(j); err != nil {
+			b.Fatalf("Error adding job: %v", err)
+		}
+		if err := sr.removeJob(j.Id); err != nil {
+			b.Fatalf("Error removing job: %v", err)
+		}
+	}
+}
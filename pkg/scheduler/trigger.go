@@ -0,0 +1,88 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Tarick/tscheduler/pkg/job"
+)
+
+// ValidateTriggers checks that jobs trigger existing jobs and triggers have no cycles, which would run jobs endlessly.
+// Triggers are triggered job IDs by job ID, every job must be present.
+func ValidateTriggers(triggers map[string][]string) error {
+	for id, triggered := range triggers {
+		for _, t := range triggered {
+			if _, ok := triggers[t]; !ok {
+				return fmt.Errorf("job %v triggers unknown job %v", id, t)
+			}
+		}
+	}
+	if cycle := findCycle(triggers); cycle != nil {
+		return fmt.Errorf("jobs trigger cycle: %s", strings.Join(cycle, " -> "))
+	}
+	return nil
+}
+
+// ValidateStartable checks that every unscheduled job, i.e. without schedule and fixed delay, depends on other jobs
+// or is triggered by them, otherwise it would never run, e.g. due to the missing or misspelled schedule.
+// Dependencies and triggers are upstream and triggered job IDs by job ID.
+func ValidateStartable(unscheduled []string, dependencies, triggers map[string][]string) error {
+	triggeredJobs := make(map[string]bool)
+	for _, ids := range triggers {
+		for _, id := range ids {
+			triggeredJobs[id] = true
+		}
+	}
+	for _, id := range unscheduled {
+		if len(dependencies[id]) == 0 && !triggeredJobs[id] {
+			return fmt.Errorf("job %v has no schedule, fixed delay and dependencies and isn't triggered by other jobs, it would never run", id)
+		}
+	}
+	return nil
+}
+
+// triggered returns IDs of jobs, which the finished run triggers by its status
+func triggered(t job.Triggers, status string) []string {
+	var ids []string
+	switch status {
+	case RunSuccess:
+		ids = append(ids, t.OnSuccess...)
+	case RunFailed:
+		ids = append(ids, t.OnFailure...)
+	default:
+		// Skipped run has not run at all
+		return nil
+	}
+	return append(ids, t.OnComplete...)
+}
+
+// startTriggered starts jobs, triggered by the final attempt of the finished run
+func (sr *Scheduler) startTriggered(f finishedRun) {
+	ids := triggered(f.job.Triggers(), f.status)
+	// Nothing is started on shutdown
+	if len(ids) == 0 || sr.jobsCtx.Err() != nil {
+		return
+	}
+	for _, id := range ids {
+		j := sr.getJob(id)
+		if j == nil {
+			sr.logger.Error("Job \"", f.job.Id, "\" run ", f.run.ID, " triggers job ", id, ", which is not registered")
+			continue
+		}
+		sr.logger.Info("Starting job ", id, ", triggered by job ", f.job.Id, " run ", f.run.ID, " with status ", f.status)
+		run := job.RunInfo{
+			ID:        newRunID(),
+			Scheduled: f.at,
+			Started:   sr.clock.Now(),
+			Attempt:   1,
+			Trigger: job.Trigger{
+				JobID:  f.job.Id,
+				RunID:  f.run.ID,
+				Status: f.status,
+			},
+		}
+		j.SetLastRun(run.Started)
+		sr.startWorkflow(j, &run)
+		sr.startRun(j, run)
+	}
+}
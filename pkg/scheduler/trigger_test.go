@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Tarick/tscheduler/pkg/job"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+)
+
+func TestValidateTriggers(t *testing.T) {
+	testData := []struct {
+		triggers map[string][]string
+		expected string
+	}{
+		{map[string][]string{"a": {"b", "c"}, "b": {"c"}, "c": nil}, ""},
+		{map[string][]string{"a": {"x"}}, "job a triggers unknown job x"},
+		{map[string][]string{"a": {"b"}, "b": {"a"}}, "jobs trigger cycle: a -> b -> a"},
+	}
+	for _, d := range testData {
+		err := ValidateTriggers(d.triggers)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != d.expected {
+			t.Errorf("Test for %v failed!\nEXPECTED: \n %v\nNEW: \n %v\n", d.triggers, d.expected, got)
+		}
+	}
+}
+
+func TestValidateStartable(t *testing.T) {
+	dependencies := map[string][]string{"load": {"extract"}}
+	triggers := map[string][]string{"extract": {"notify"}, "load": nil, "notify": nil}
+	testData := []struct {
+		unscheduled []string
+		expected    string
+	}{
+		{nil, ""},
+		{[]string{"load", "notify"}, ""},
+		{[]string{"notify", "cleanup"}, "job cleanup has no schedule, fixed delay and dependencies and isn't triggered by other jobs, it would never run"},
+	}
+	for _, d := range testData {
+		err := ValidateStartable(d.unscheduled, dependencies, triggers)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != d.expected {
+			t.Errorf("Test for %v failed!\nEXPECTED: \n %v\nNEW: \n %v\n", d.unscheduled, d.expected, got)
+		}
+	}
+}
+
+func TestTriggers(t *testing.T) {
+	sr := New(zap.NewNop().Sugar())
+	schedule, err := job.NewSchedule(job.ScheduleSpec{Text: "every day"})
+	if err != nil {
+		t.Fatalf("Error creating schedule: %v", err)
+	}
+	started := make(chan job.RunInfo, 10)
+	command := func(err error) func(context.Context, job.RunInfo) error {
+		return func(ctx context.Context, run job.RunInfo) error {
+			started <- run
+			return err
+		}
+	}
+	backup, err := job.New("backup", command(errors.New("failed")), []job.Schedule{schedule}, job.WithTriggers(job.Triggers{
+		OnSuccess:  []string{"notify"},
+		OnFailure:  []string{"cleanup"},
+		OnComplete: []string{"report"},
+	}))
+	if err != nil {
+		t.Fatalf("Error creating job: %v", err)
+	}
+	sr.AddJob(backup)
+	for _, id := range []string{"notify", "cleanup", "report"} {
+		j, err := job.New(id, command(nil), nil)
+		if err != nil {
+			t.Fatalf("Error creating job %v: %v", id, err)
+		}
+		sr.AddJob(j)
+	}
+	sr.startJob(backup, time.Now())
+	root := <-started
+	// Failed run triggers cleanup and report jobs
+	var got []job.Trigger
+	for len(got) != 2 {
+		select {
+		case <-sr.wake:
+			sr.processFinished()
+		case run := <-started:
+			got = append(got, run.Trigger)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Triggered jobs haven't started, got %v", got)
+		}
+	}
+	trigger := job.Trigger{JobID: "backup", RunID: root.ID, Status: RunFailed}
+	expected := []job.Trigger{trigger, trigger}
+	if diff := cmp.Diff(got, expected); diff != "" {
+		t.Errorf("Test for triggers failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", expected, got, diff)
+	}
+	if diff := cmp.Diff(triggered(backup.Triggers(), RunSuccess), []string{"notify", "report"}); diff != "" {
+		t.Errorf("Test for success triggers failed!\nDIFF: %v\n", diff)
+	}
+	if ids := triggered(backup.Triggers(), RunSkipped); ids != nil {
+		t.Errorf("Skipped run must not trigger jobs, got %v", ids)
+	}
+}
@@ -0,0 +1,310 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Tarick/tscheduler/pkg/job"
+)
+
+// Workflow job states, besides run statuses
+const (
+	WorkflowPending        = "pending"
+	WorkflowRunning        = "running"
+	WorkflowUpstreamFailed = "upstream_failed"
+)
+
+// WorkflowRun is the run of the root job and all the jobs, which depend on it directly or indirectly
+type WorkflowRun struct {
+	// ID is the run ID of the root job
+	ID       string
+	Root     string
+	Started  time.Time
+	Finished time.Time
+	// Status is WorkflowRunning, RunSuccess if all jobs have succeeded, or RunFailed
+	Status string
+	// Jobs are states of the workflow jobs by job ID
+	Jobs map[string]string
+	// upstreams of every job within the workflow
+	upstreams map[string][]string
+}
+
+func (w WorkflowRun) String() string {
+	ids := make([]string, 0, len(w.Jobs))
+	for id := range w.Jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	states := make([]string, len(ids))
+	for i, id := range ids {
+		states[i] = id + ": " + w.Jobs[id]
+	}
+	s := fmt.Sprintf("%v (%s) %s: started %v", w.ID, w.Root, w.Status, w.Started.Format(time.RFC3339))
+	if !w.Finished.IsZero() {
+		s += fmt.Sprintf(", took %v", w.Finished.Sub(w.Started))
+	}
+	return s + "\n  " + strings.Join(states, "\n  ")
+}
+
+// copy returns the copy, safe to read while the workflow runs
+func (w *WorkflowRun) copy() WorkflowRun {
+	c := *w
+	c.Jobs = make(map[string]string, len(w.Jobs))
+	for id, state := range w.Jobs {
+		c.Jobs[id] = state
+	}
+	return c
+}
+
+// ValidateDependencies checks that jobs depend on existing jobs, dependencies have no cycles and all upstream jobs of the job
+// descend from the same root job, as the workflow run is started by its root job.
+// Dependencies are upstream job IDs by job ID, every job must be present.
+func ValidateDependencies(dependencies map[string][]string) error {
+	for id, upstreams := range dependencies {
+		for _, upstream := range upstreams {
+			if _, ok := dependencies[upstream]; !ok {
+				return fmt.Errorf("job %v depends on unknown job %v", id, upstream)
+			}
+		}
+	}
+	if cycle := findCycle(dependencies); cycle != nil {
+		return fmt.Errorf("jobs dependency cycle: %s", strings.Join(cycle, " -> "))
+	}
+	ids := make([]string, 0, len(dependencies))
+	for id := range dependencies {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	roots := make(map[string][]string, len(dependencies))
+	for _, id := range ids {
+		if r := workflowRoots(dependencies, id, roots); len(r) > 1 {
+			return fmt.Errorf("job %v depends on jobs of different workflows with root jobs %s, "+
+				"upstream jobs must descend from the same root job", id, strings.Join(r, ", "))
+		}
+	}
+	return nil
+}
+
+// workflowRoots returns sorted IDs of jobs without dependencies, the job descends from, or the job itself if it has no dependencies.
+// Dependencies must have no cycles, roots are memoized by job ID.
+func workflowRoots(dependencies map[string][]string, id string, roots map[string][]string) []string {
+	if r, ok := roots[id]; ok {
+		return r
+	}
+	upstreams := dependencies[id]
+	if len(upstreams) == 0 {
+		roots[id] = []string{id}
+		return roots[id]
+	}
+	seen := make(map[string]bool)
+	var r []string
+	for _, upstream := range upstreams {
+		for _, root := range workflowRoots(dependencies, upstream, roots) {
+			if !seen[root] {
+				seen[root] = true
+				r = append(r, root)
+			}
+		}
+	}
+	sort.Strings(r)
+	roots[id] = r
+	return r
+}
+
+// findCycle returns the first cycle of the jobs graph, starting and ending with the same job ID, nil if there is none.
+// Graph holds linked job IDs by job ID.
+func findCycle(graph map[string][]string) []string {
+	ids := make([]string, 0, len(graph))
+	for id := range graph {
+		ids = append(ids, id)
+	}
+	// Stable result for the same config
+	sort.Strings(ids)
+	// Depth first search, the job on the current path means the cycle
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(graph))
+	var path []string
+	var visit func(id string) []string
+	visit = func(id string) []string {
+		switch state[id] {
+		case visiting:
+			for i := range path {
+				if path[i] == id {
+					return append(append([]string{}, path[i:]...), id)
+				}
+			}
+		case visited:
+			return nil
+		}
+		state[id] = visiting
+		path = append(path, id)
+		for _, linked := range graph[id] {
+			if cycle := visit(linked); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+		return nil
+	}
+	for _, id := range ids {
+		if cycle := visit(id); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// Workflows returns the running workflow runs and the last finished ones, the newest first
+func (sr *Scheduler) Workflows() []WorkflowRun {
+	sr.workflowMutex.Lock()
+	defer sr.workflowMutex.Unlock()
+	var result []WorkflowRun
+	for _, w := range sr.workflows {
+		result = append(result, w.copy())
+	}
+	sort.Slice(result, func(i, k int) bool { return result[i].Started.After(result[k].Started) })
+	for i := len(sr.finishedWorkflows) - 1; i >= 0; i-- {
+		result = append(result, sr.finishedWorkflows[i].copy())
+	}
+	return result
+}
+
+// startWorkflow starts workflow run of the root job run, if other jobs depend on the job
+func (sr *Scheduler) startWorkflow(root *job.Job, run *job.RunInfo) {
+	upstreams := make(map[string][]string)
+	// Jobs, reachable from the root by dependencies
+	queue := []string{root.Id}
+	upstreams[root.Id] = nil
+	for len(queue) != 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, dependent := range sr.dependents[id] {
+			if _, ok := upstreams[dependent]; !ok {
+				queue = append(queue, dependent)
+			}
+			upstreams[dependent] = append(upstreams[dependent], id)
+		}
+	}
+	if len(upstreams) == 1 {
+		return
+	}
+	w := &WorkflowRun{
+		ID:        run.ID,
+		Root:      root.Id,
+		Started:   run.Started,
+		Status:    WorkflowRunning,
+		Jobs:      make(map[string]string, len(upstreams)),
+		upstreams: upstreams,
+	}
+	for id := range upstreams {
+		w.Jobs[id] = WorkflowPending
+	}
+	w.Jobs[root.Id] = WorkflowRunning
+	run.WorkflowID = w.ID
+	sr.workflowMutex.Lock()
+	sr.workflows[w.ID] = w
+	sr.workflowMutex.Unlock()
+	sr.logger.Info("Started workflow run ", w.ID, " of job ", root.Id, " with ", len(upstreams), " jobs")
+}
+
+// finishWorkflowJob updates the workflow with the job final run status and starts the jobs, whose upstream jobs have all succeeded
+func (sr *Scheduler) finishWorkflowJob(f finishedRun) {
+	sr.workflowMutex.Lock()
+	w, ok := sr.workflows[f.run.WorkflowID]
+	if !ok {
+		sr.workflowMutex.Unlock()
+		return
+	}
+	w.Jobs[f.job.Id] = f.status
+	if f.status != RunSuccess {
+		sr.failDownstream(w, f.job.Id)
+	}
+	var downstream []*job.Job
+	for id, state := range w.Jobs {
+		if state != WorkflowPending || !sr.upstreamsSucceeded(w, id) {
+			continue
+		}
+		j := sr.getJob(id)
+		if j == nil {
+			sr.logger.Error("Workflow run ", w.ID, " job ", id, " is not registered anymore")
+			w.Jobs[id] = RunFailed
+			sr.failDownstream(w, id)
+			continue
+		}
+		w.Jobs[id] = WorkflowRunning
+		downstream = append(downstream, j)
+	}
+	workflowID := w.ID
+	sr.completeWorkflow(w, f.at)
+	sr.workflowMutex.Unlock()
+	// Runs are started without the workflow lock, so Workflows callers don't wait for the runs admission
+	sort.Slice(downstream, func(i, k int) bool { return downstream[i].Id < downstream[k].Id })
+	for _, j := range downstream {
+		sr.logger.Info("Starting job ", j.Id, " of workflow run ", workflowID)
+		j.SetLastRun(sr.clock.Now())
+		sr.startRun(j, job.RunInfo{
+			ID:         newRunID(),
+			Scheduled:  f.at,
+			Started:    sr.clock.Now(),
+			Attempt:    1,
+			WorkflowID: workflowID,
+		})
+	}
+}
+
+// upstreamsSucceeded reports if all upstream jobs within the workflow have succeeded
+func (sr *Scheduler) upstreamsSucceeded(w *WorkflowRun, id string) bool {
+	for _, upstream := range w.upstreams[id] {
+		if w.Jobs[upstream] != RunSuccess {
+			return false
+		}
+	}
+	return true
+}
+
+// failDownstream marks pending jobs, which depend on the job directly or indirectly, as failed upstream
+func (sr *Scheduler) failDownstream(w *WorkflowRun, failed string) {
+	for id, upstreams := range w.upstreams {
+		for _, upstream := range upstreams {
+			if upstream == failed && w.Jobs[id] == WorkflowPending {
+				w.Jobs[id] = WorkflowUpstreamFailed
+				sr.failDownstream(w, id)
+			}
+		}
+	}
+}
+
+// completeWorkflow moves the workflow to the finished ones, when all its jobs are done
+func (sr *Scheduler) completeWorkflow(w *WorkflowRun, t time.Time) {
+	status := RunSuccess
+	for _, state := range w.Jobs {
+		switch state {
+		case WorkflowPending, WorkflowRunning:
+			return
+		case RunSuccess:
+		default:
+			status = RunFailed
+		}
+	}
+	w.Status, w.Finished = status, t
+	delete(sr.workflows, w.ID)
+	sr.finishedWorkflows = append(sr.finishedWorkflows, w)
+	if len(sr.finishedWorkflows) > DefaultHistorySize {
+		sr.finishedWorkflows = sr.finishedWorkflows[1:]
+	}
+	sr.logger.Info("Workflow run ", w.ID, " of job ", w.Root, " finished with status ", status)
+}
+
+// getJob returns registered job by ID, nil if not found
+func (sr *Scheduler) getJob(id string) *job.Job {
+	if e, ok := sr.jobs[id]; ok {
+		return e.job
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Tarick/tscheduler/pkg/job"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+)
+
+func TestValidateDependencies(t *testing.T) {
+	testData := []struct {
+		dependencies map[string][]string
+		expected     string
+	}{
+		{map[string][]string{"a": nil, "b": {"a"}, "c": {"a", "b"}}, ""},
+		{map[string][]string{"a": nil, "b": {"x"}}, "job b depends on unknown job x"},
+		{map[string][]string{"a": {"c"}, "b": {"a"}, "c": {"b"}}, "jobs dependency cycle: a -> c -> b -> a"},
+		{map[string][]string{"a": {"a"}}, "jobs dependency cycle: a -> a"},
+		// Diamond of the single root is one workflow
+		{map[string][]string{"a": nil, "b": {"a"}, "c": {"a"}, "d": {"b", "c"}}, ""},
+		// Join of separately scheduled roots never runs in the same workflow run
+		{map[string][]string{"a": nil, "b": nil, "d": {"a", "b"}},
+			"job d depends on jobs of different workflows with root jobs a, b, upstream jobs must descend from the same root job"},
+		{map[string][]string{"a": nil, "b": nil, "c": {"b"}, "d": {"a"}, "e": {"c", "d"}},
+			"job e depends on jobs of different workflows with root jobs a, b, upstream jobs must descend from the same root job"},
+	}
+	for _, d := range testData {
+		err := ValidateDependencies(d.dependencies)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != d.expected {
+			t.Errorf("Test for %v failed!\nEXPECTED: \n %v\nNEW: \n %v\n", d.dependencies, d.expected, got)
+		}
+	}
+}
+
+func TestWorkflow(t *testing.T) {
+	sr := New(zap.NewNop().Sugar())
+	schedule, err := job.NewSchedule(job.ScheduleSpec{Text: "every day"})
+	if err != nil {
+		t.Fatalf("Error creating schedule: %v", err)
+	}
+	started := make(chan string, 10)
+	command := func(err error) func(context.Context, job.RunInfo) error {
+		return func(ctx context.Context, run job.RunInfo) error {
+			started <- run.WorkflowID
+			return err
+		}
+	}
+	jobs := []struct {
+		id        string
+		err       error
+		dependsOn []string
+	}{
+		{"extract", nil, nil},
+		{"transform", nil, []string{"extract"}},
+		{"check", errors.New("failed"), []string{"extract"}},
+		{"load", nil, []string{"transform", "check"}},
+		{"report", nil, []string{"load"}},
+	}
+	for _, d := range jobs {
+		var j *job.Job
+		if d.dependsOn == nil {
+			j, err = job.New(d.id, command(d.err), []job.Schedule{schedule})
+		} else {
+			j, err = job.New(d.id, command(d.err), nil, job.WithDependsOn(d.dependsOn...))
+		}
+		if err != nil {
+			t.Fatalf("Error creating job %v: %v", d.id, err)
+		}
+		sr.AddJob(j)
+	}
+	sr.startJob(sr.getJob("extract"), time.Now())
+	for len(sr.workflows) != 0 {
+		select {
+		case <-sr.wake:
+			sr.processFinished()
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Workflow hasn't finished: %v", sr.Workflows())
+		}
+	}
+	workflows := sr.Workflows()
+	if len(workflows) != 1 {
+		t.Fatalf("Expected one finished workflow, got %v", workflows)
+	}
+	expected := map[string]string{
+		"extract":   RunSuccess,
+		"transform": RunSuccess,
+		"check":     RunFailed,
+		"load":      WorkflowUpstreamFailed,
+		"report":    WorkflowUpstreamFailed,
+	}
+	if diff := cmp.Diff(workflows[0].Jobs, expected); diff != "" {
+		t.Errorf("Test for workflow failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", expected, workflows[0], diff)
+	}
+	if workflows[0].Status != RunFailed {
+		t.Errorf("Workflow must fail, got %v", workflows[0].Status)
+	}
+	if len(started) != 3 {
+		t.Errorf("Expected 3 started jobs, got %d", len(started))
+	}
+	for len(started) != 0 {
+		if id := <-started; id != workflows[0].ID {
+			t.Errorf("Run must belong to workflow %v, got %v", workflows[0].ID, id)
+		}
+	}
+}
+
+func TestRemoveWorkflowJob(t *testing.T) {
+	sr := New(zap.NewNop().Sugar())
+	release := make(chan struct{})
+	extract, err := job.New("extract", func(ctx context.Context, run job.RunInfo) error { return nil }, nil)
+	if err != nil {
+		t.Fatalf("Error creating job: %v", err)
+	}
+	transform, err := job.New("transform", func(ctx context.Context, run job.RunInfo) error {
+		<-release
+		return nil
+	}, nil, job.WithDependsOn("extract"), job.WithConcurrencyPolicy(job.ConcurrencyPolicy{Max: 1, Policy: job.ConcurrencyQueue}))
+	if err != nil {
+		t.Fatalf("Error creating job: %v", err)
+	}
+	sr.AddJob(extract)
+	sr.AddJob(transform)
+	// Workflow run of transform is queued behind the running one
+	sr.startRun(transform, job.RunInfo{ID: "running", Started: time.Now(), Attempt: 1})
+	sr.startJob(extract, time.Now())
+	for len(sr.queued["transform"]) == 0 {
+		select {
+		case <-sr.wake:
+			sr.processFinished()
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Workflow run of transform isn't queued: %v", sr.Workflows())
+		}
+	}
+	if err := sr.removeJob("transform"); err != nil {
+		t.Fatalf("Error removing job: %v", err)
+	}
+	close(release)
+	workflows := sr.Workflows()
+	if len(workflows) != 1 || workflows[0].Status != RunFailed {
+		t.Fatalf("Workflow must fail with the removed job, got %v", workflows)
+	}
+	expected := map[string]string{"extract": RunSuccess, "transform": RunFailed}
+	if diff := cmp.Diff(workflows[0].Jobs, expected); diff != "" {
+		t.Errorf("Test for workflow with removed job failed!\nEXPECTED: \n %v\nNEW: \n %v\nDIFF: %v\n", expected, workflows[0], diff)
+	}
+	sr.jobWaiter.Wait()
+}